@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"context"
+	"math-discard-card/card"
+	"math-discard-card/utility"
+	"sort"
+)
+
+// 花色互換後相同的一組起手牌
+type handClass struct {
+	hand  []*card.Card // 代表的手牌, 依 Idx 由小到大排序
+	count int64        // 屬於這組的起手牌組合數
+}
+
+// suits 之間互換的所有排列, 結果以原本的花色為索引
+func suitPermutations(suits []card.SuitType) [][4]card.SuitType {
+	perms := [][4]card.SuitType{}
+	order := make([]int, len(suits))
+	used := make([]bool, len(suits))
+	var helper func(pos int)
+	helper = func(pos int) {
+		if pos == len(suits) {
+			var perm [4]card.SuitType
+			for i, j := range order {
+				perm[suits[i]] = suits[j]
+			}
+			perms = append(perms, perm)
+			return
+		}
+		for j := range suits {
+			if !used[j] {
+				used[j] = true
+				order[pos] = j
+				helper(pos + 1)
+				used[j] = false
+			}
+		}
+	}
+	helper(0)
+	return perms
+}
+
+// 手牌在花色互換下的標準形式: 所有花色排列中 Idx 排序後字典序最小的一個
+func canonicalHand(cards []*card.Card, perms [][4]card.SuitType) ([]*card.Card, string) {
+	var best []int
+	idxs := make([]int, len(cards))
+	for _, perm := range perms {
+		for i, c := range cards {
+			if c.IsJoker() {
+				idxs[i] = c.Idx
+			} else {
+				idxs[i] = int(perm[c.Suit])*13 + c.Number
+			}
+		}
+		sort.Ints(idxs)
+		if best == nil || lessInts(idxs, best) {
+			best = append(best[:0], idxs...)
+		}
+	}
+
+	hand := make([]*card.Card, len(best))
+	key := make([]byte, len(best))
+	for i, idx := range best {
+		if idx > 52 {
+			hand[i] = card.NewJoker(idx - 52)
+		} else {
+			hand[i] = card.CardFromIdx(idx)
+		}
+		key[i] = byte(idx)
+	}
+	return hand, string(key)
+}
+
+func lessInts(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// 分組時每段工作處理幾組起手牌
+const canonicalChunkSize = 1 << 14
+
+// 一段起手牌的分組結果, 依第一次出現的順序排列
+type classChunk struct {
+	keys    []string
+	classes []*handClass
+}
+
+// 列舉牌組中所有 handSize 張的起手牌並依花色互換分組, 依第一次出現的順序排列
+//
+// 依 engine 平行列舉並回報進度, 各段依順序合併, 結果與分段方式無關; ctx 取消時回傳錯誤
+func canonicalClasses(ctx context.Context, engine *Engine, deck []*card.Card, handSize int) ([]*handClass, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	suits := []card.SuitType{}
+	seen := make(map[card.SuitType]bool)
+	for _, c := range deck {
+		if !c.IsJoker() && !seen[c.Suit] {
+			seen[c.Suit] = true
+			suits = append(suits, c.Suit)
+		}
+	}
+	perms := suitPermutations(suits)
+
+	classes := []*handClass{}
+	byKey := make(map[string]*handClass)
+	err := RunParallel(ctx, engine, utility.Binomial(len(deck), handSize), canonicalChunkSize, func(ctx context.Context, start, end int64) (classChunk, error) {
+		var chunk classChunk
+		local := make(map[string]*handClass)
+		err := utility.NewCombinations(deck, handSize).EachRange(start, end, func(hand []*card.Card) bool {
+			canonical, key := canonicalHand(hand, perms)
+			class, ok := local[key]
+			if !ok {
+				class = &handClass{hand: canonical}
+				local[key] = class
+				chunk.keys = append(chunk.keys, key)
+				chunk.classes = append(chunk.classes, class)
+			}
+			class.count++
+			return true
+		})
+		return chunk, err
+	}, func(chunk classChunk) {
+		for i, key := range chunk.keys {
+			if class, ok := byKey[key]; ok {
+				class.count += chunk.classes[i].count
+				continue
+			}
+			byKey[key] = chunk.classes[i]
+			classes = append(classes, chunk.classes[i])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
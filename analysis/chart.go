@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math-discard-card/card"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChartEntry 策略表中的一組起手牌(花色互換後相同的手牌視為同一組)
+type ChartEntry struct {
+	Hand        []*card.Card  `json:"hand"`         // 代表的手牌, 依 Idx 由小到大排序
+	HandType    card.HandType `json:"hand_type"`    // 起手牌型
+	Count       int64         `json:"count"`        // 屬於這組的起手牌組合數
+	Probability float64       `json:"probability"`  // 拿到這組起手牌的機率
+	Hold        []*card.Card  `json:"hold"`         // 最佳選擇要留下的牌
+	Discard     []int         `json:"discard"`      // 最佳選擇要換掉的位置, 結算時為空
+	Settle      bool          `json:"settle"`       // 最佳選擇為直接結算
+	EV          float64       `json:"ev"`           // 依最佳策略的期望淨點數, 不含開局花費
+	SettleValue float64       `json:"settle_value"` // 直接結算拿回的點數
+}
+
+// StrategyChart 列舉所有花色互換後不同的起手牌, 以最佳策略決定留牌, 依期望值由大到小排列成留牌優先順序
+//
+// 每組起手牌都要跑一次最佳策略, 標準牌組的計算量很大
+func (a *Analyzer) StrategyChart(config GameConfig) ([]ChartEntry, error) {
+	return a.StrategyChartContext(context.Background(), config)
+}
+
+// StrategyChartContext 同 StrategyChart, 可以用 ctx 取消, 依 Analyzer.Engine 平行計算
+func (a *Analyzer) StrategyChartContext(ctx context.Context, config GameConfig) ([]ChartEntry, error) {
+	a = a.forGame(config)
+	deck := a.Rules.NewDeck()
+	handSize, err := config.checkHandSize(len(deck))
+	if err != nil {
+		return nil, err
+	}
+	planner := NewPlanner(a.serial(), config.DiscardCost(), config.MaxRounds)
+
+	classes, err := canonicalClasses(ctx, a.Engine, deck, handSize)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, class := range classes {
+		total += class.count
+	}
+	entries := make([]ChartEntry, 0, len(classes))
+	err = RunParallel(ctx, a.Engine, int64(len(classes)), rtpChunkSize, func(ctx context.Context, start, end int64) ([]ChartEntry, error) {
+		chunk := make([]ChartEntry, 0, end-start)
+		for _, class := range classes[start:end] {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			decision, err := planner.Decide(State{Hand: class.hand})
+			if err != nil {
+				return nil, err
+			}
+			entry := ChartEntry{
+				Hand:        class.hand,
+				HandType:    a.Rules.GetHandType(class.hand),
+				Count:       class.count,
+				Probability: float64(class.count) / float64(total),
+				Discard:     []int{},
+				Settle:      decision.Settle,
+				EV:          decision.Value,
+				SettleValue: decision.SettleValue,
+			}
+			if !decision.Settle {
+				entry.Discard = decision.Discard
+			}
+			entry.Hold, _ = keptCards(class.hand, entry.Discard)
+			chunk = append(chunk, entry)
+		}
+		return chunk, nil
+	}, func(chunk []ChartEntry) {
+		entries = append(entries, chunk...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].EV > entries[j].EV
+	})
+	return entries, nil
+}
+
+// WriteChartCSV 以 CSV 輸出策略表, 第一列為欄位名稱
+func WriteChartCSV(w io.Writer, entries []ChartEntry) error {
+	writer := csv.NewWriter(w)
+	header := []string{"rank", "hand", "hand_type", "count", "probability", "hold", "discard", "ev", "settle_value"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		handType, err := entry.HandType.MarshalText()
+		if err != nil {
+			return err
+		}
+		discard := make([]string, len(entry.Discard))
+		for j, idx := range entry.Discard {
+			discard[j] = strconv.Itoa(idx)
+		}
+		record := []string{
+			strconv.Itoa(i + 1),
+			cardsNotation(entry.Hand),
+			string(handType),
+			strconv.FormatInt(entry.Count, 10),
+			strconv.FormatFloat(entry.Probability, 'g', -1, 64),
+			cardsNotation(entry.Hold),
+			strings.Join(discard, " "),
+			strconv.FormatFloat(entry.EV, 'f', 10, 64),
+			strconv.FormatFloat(entry.SettleValue, 'f', 10, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteChartJSON 以 JSON 陣列輸出策略表
+func WriteChartJSON(w io.Writer, entries []ChartEntry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+func cardsNotation(cards []*card.Card) string {
+	strs := make([]string, len(cards))
+	for i, c := range cards {
+		strs[i] = c.Notation()
+	}
+	return strings.Join(strs, " ")
+}
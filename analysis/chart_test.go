@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"math-discard-card/card"
+	"testing"
+)
+
+func TestStrategyChart(t *testing.T) {
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	analyzer := NewAnalyzer(rules, paytable)
+	config := GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: 1}
+
+	entries, err := analyzer.StrategyChart(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var count int64
+	var ev float64
+	for i, entry := range entries {
+		count += entry.Count
+		ev += entry.Probability * entry.EV
+		if i > 0 && entry.EV > entries[i-1].EV {
+			t.Errorf("entries are not sorted at %d", i)
+		}
+		if len(entry.Hold)+len(entry.Discard) != 5 {
+			t.Errorf("expected hold and discard to cover the hand, got %v and %v", entry.Hold, entry.Discard)
+		}
+		if entry.HandType == card.FullHouse && (!entry.Settle || entry.EV != 10) {
+			t.Errorf("expected to settle full house %v, got %+v", entry.Hand, entry)
+		}
+	}
+	if count != 126 {
+		t.Errorf("expected 126 starting hands, got %d", count)
+	}
+	// 策略表的期望值加總要跟 RTP 一致: 拿回點數 - 換牌花費
+	report, err := analyzer.RTP(config, NewPlanner(analyzer, config.DiscardCost(), config.MaxRounds))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := report.ExpectedPayout - (report.ExpectedCost - float64(config.GameCost)); math.Abs(ev-expected) > 1e-9 {
+		t.Errorf("expected chart EV %f to match RTP report %f", ev, expected)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteChartCSV(&buf, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != len(entries)+1 || records[0][0] != "rank" || records[1][0] != "1" {
+		t.Errorf("unexpected CSV %v", records[:2])
+	}
+
+	buf.Reset()
+	if err := WriteChartJSON(&buf, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []ChartEntry
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decoded) != len(entries) || decoded[0].Hand[0].Idx != entries[0].Hand[0].Idx {
+		t.Errorf("expected JSON to round trip")
+	}
+}
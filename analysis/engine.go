@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// 預設每段工作的大小
+const defaultChunkSize = 1 << 14
+
+// Engine 平行列舉的設定: 把 [0, total) 的排名切成多段交給 worker pool 處理, 再依段落順序合併結果
+//
+// 合併順序固定, 所以浮點數加總的結果不會因為 worker 數量或完成順序而不同
+type Engine struct {
+	Workers   int                     // worker 數量, 0 為 CPU 數量
+	ChunkSize int64                   // 每段工作的大小, 0 為預設值
+	Progress  func(done, total int64) // 每合併一段就呼叫一次, 只會在呼叫端的 goroutine 執行
+}
+
+// NewEngine 建立使用所有 CPU 的平行列舉設定
+func NewEngine() *Engine {
+	return &Engine{Workers: runtime.NumCPU()}
+}
+
+func (e *Engine) workers() int {
+	switch {
+	case e == nil:
+		return 1
+	case e.Workers <= 0:
+		return runtime.NumCPU()
+	}
+	return e.Workers
+}
+
+func (e *Engine) chunkSize(defaultSize int64) int64 {
+	if e == nil || e.ChunkSize <= 0 {
+		return defaultSize
+	}
+	return e.ChunkSize
+}
+
+func (e *Engine) progress(done, total int64) {
+	if e != nil && e.Progress != nil {
+		e.Progress(done, total)
+	}
+}
+
+// 一段工作的結果
+type chunkResult[R any] struct {
+	idx    int
+	result R
+	err    error
+}
+
+// RunParallel 把 [0, total) 切成多段, 由 engine 的 worker 平行執行 work, 再依段落順序呼叫 merge
+//
+// engine 為 nil 或只有1個 worker 時直接在呼叫端的 goroutine 依序執行, 不建立 goroutine;
+// ctx 取消或任何一段回傳錯誤時停止並回傳該錯誤
+func RunParallel[R any](ctx context.Context, engine *Engine, total, defaultChunk int64,
+	work func(ctx context.Context, start, end int64) (R, error), merge func(result R)) error {
+	if err := ctx.Err(); err != nil || total <= 0 {
+		return err
+	}
+	size := engine.chunkSize(defaultChunk)
+	chunks := int((total + size - 1) / size)
+	bounds := func(idx int) (int64, int64) {
+		start := int64(idx) * size
+		return start, min(start+size, total)
+	}
+
+	if engine.workers() == 1 || chunks == 1 {
+		var done int64
+		for idx := 0; idx < chunks; idx++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			start, end := bounds(idx)
+			result, err := work(ctx, start, end)
+			if err != nil {
+				return err
+			}
+			merge(result)
+			done += end - start
+			engine.progress(done, total)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan chunkResult[R])
+	var wg sync.WaitGroup
+	for i := 0; i < min(engine.workers(), chunks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				start, end := bounds(idx)
+				result, err := work(ctx, start, end)
+				select {
+				case results <- chunkResult[R]{idx: idx, result: result, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for idx := 0; idx < chunks; idx++ {
+			select {
+			case jobs <- idx:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// 先完成的段落暫存起來, 依順序合併
+	pending := make(map[int]R)
+	next := 0
+	var done int64
+	var firstErr error
+	for chunk := range results {
+		if firstErr != nil {
+			continue
+		}
+		if chunk.err != nil {
+			firstErr = chunk.err
+			cancel()
+			continue
+		}
+		pending[chunk.idx] = chunk.result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			merge(result)
+			start, end := bounds(next)
+			done += end - start
+			engine.progress(done, total)
+			next++
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	if next < chunks {
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"math-discard-card/card"
+	"reflect"
+	"testing"
+)
+
+func TestRunParallel(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  *Engine
+		total   int64
+		chunk   int64
+		workErr error
+	}{
+		{"Sequential", nil, 1000, 7, nil},
+		{"OneWorker", &Engine{Workers: 1}, 1000, 7, nil},
+		{"ManyWorkers", &Engine{Workers: 8}, 1000, 7, nil},
+		{"CustomChunk", &Engine{Workers: 4, ChunkSize: 3}, 100, 7, nil},
+		{"Empty", &Engine{Workers: 4}, 0, 7, nil},
+		{"Error", &Engine{Workers: 4}, 1000, 7, errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		var progress []int64
+		if tt.engine != nil {
+			tt.engine.Progress = func(done, total int64) {
+				if total != tt.total {
+					t.Errorf("%s: expected progress total %d, got %d", tt.name, tt.total, total)
+				}
+				progress = append(progress, done)
+			}
+		}
+		var got []int64
+		err := RunParallel(context.Background(), tt.engine, tt.total, tt.chunk, func(ctx context.Context, start, end int64) ([]int64, error) {
+			if tt.workErr != nil && start > 0 {
+				return nil, tt.workErr
+			}
+			values := []int64{}
+			for i := start; i < end; i++ {
+				values = append(values, i)
+			}
+			return values, nil
+		}, func(values []int64) {
+			got = append(got, values...)
+		})
+
+		if tt.workErr != nil {
+			if !errors.Is(err, tt.workErr) {
+				t.Errorf("%s: expected error %v, got %v", tt.name, tt.workErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if int64(len(got)) != tt.total {
+			t.Fatalf("%s: expected %d values, got %d", tt.name, tt.total, len(got))
+		}
+		for i, value := range got {
+			if value != int64(i) {
+				t.Fatalf("%s: expected values merged in order, got %d at %d", tt.name, value, i)
+			}
+		}
+		if tt.engine != nil && tt.total > 0 && progress[len(progress)-1] != tt.total {
+			t.Errorf("%s: expected progress to reach %d, got %v", tt.name, tt.total, progress)
+		}
+	}
+}
+
+func TestRunParallelCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := RunParallel(ctx, &Engine{Workers: 4}, 1000, 7, func(ctx context.Context, start, end int64) (int, error) {
+		return 0, nil
+	}, func(int) {})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	analyzer := NewAnalyzer(nil, nil)
+	if _, err := analyzer.DiscardOutcomesContext(ctx, card.MustParseHand("As Kd 9h 5c 2d"), []int{0, 1}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected DiscardOutcomesContext to return context.Canceled, got %v", err)
+	}
+	config := GameConfig{GameCost: 1, MaxRounds: 1}
+	if _, err := analyzer.RTPContext(ctx, config, NeverDiscard{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected RTPContext to return context.Canceled, got %v", err)
+	}
+	if _, err := analyzer.StrategyChartContext(ctx, config); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected StrategyChartContext to return context.Canceled, got %v", err)
+	}
+}
+
+func TestCanonicalClasses(t *testing.T) {
+	// 9~K 四種花色共20張, C(20, 5) = 15504 組起手牌
+	deck := (&card.Rules{Deck: card.DeckSpec{Numbers: []int{9, 10, 11, 12, 13}}}).NewDeck()
+	expected, err := canonicalClasses(context.Background(), nil, deck, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var total int64
+	for _, class := range expected {
+		total += class.count
+	}
+	if total != 15504 {
+		t.Errorf("expected class counts to sum to 15504, got %d", total)
+	}
+
+	// 分段平行分組的結果與順序與依序分組相同, 並回報這一段的進度
+	var done int64
+	engine := &Engine{Workers: 4, ChunkSize: 100, Progress: func(d, all int64) {
+		if all != 15504 {
+			t.Errorf("expected progress total 15504, got %d", all)
+		}
+		done = d
+	}}
+	got, err := canonicalClasses(context.Background(), engine, deck, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the same classes in the same order when running in parallel")
+	}
+	if done != 15504 {
+		t.Errorf("expected progress to reach 15504, got %d", done)
+	}
+}
+
+func TestEngineDeterministic(t *testing.T) {
+	// 2~4 梅花、紅心、黑桃共9張, 兩對1、葫蘆10
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	config := GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: 2}
+
+	// 分段方式相同時, 結果與 worker 數量無關
+	sequential := NewAnalyzer(rules, paytable)
+	sequential.Engine = &Engine{Workers: 1, ChunkSize: 1}
+	parallel := NewAnalyzer(rules, paytable)
+	parallel.Engine = &Engine{Workers: 4, ChunkSize: 1}
+
+	var reports [2]*RTPReport
+	var charts [2][]ChartEntry
+	for i, analyzer := range []*Analyzer{sequential, parallel} {
+		strategy, err := analyzer.NewStrategy("optimal", config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reports[i], err = analyzer.RTP(config, strategy); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if charts[i], err = analyzer.StrategyChart(config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(reports[0], reports[1]) {
+		t.Errorf("expected identical RTP reports, got %+v and %+v", reports[0], reports[1])
+	}
+	if !reflect.DeepEqual(charts[0], charts[1]) {
+		t.Errorf("expected identical strategy charts")
+	}
+
+	hand := card.MustParseHand("Ah Kh Qh Jh 2c")
+	expected, err := NewAnalyzer(nil, nil).DiscardOutcomes(hand, []int{3, 4}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	analyzer := NewAnalyzer(nil, nil)
+	analyzer.Engine = &Engine{Workers: 4, ChunkSize: 10}
+	got, err := analyzer.DiscardOutcomes(hand, []int{3, 4}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
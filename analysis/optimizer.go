@@ -0,0 +1,272 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"math-discard-card/card"
+	"sort"
+)
+
+// HitBound 牌型出現機率的範圍, Max 為0表示沒有上限
+type HitBound struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// OptimizeOptions 賠率表搜尋條件
+type OptimizeOptions struct {
+	TargetRTP  float64                    // 目標 RTP
+	Tolerance  float64                    // 可接受的 RTP 誤差
+	HitBounds  map[card.HandType]HitBound // 最佳策略下各牌型出現機率的範圍
+	Monotonic  bool                       // 牌型越大賠率不可越低
+	MaxPay     int                        // 單一牌型的賠率上限, 0 表示不限
+	Adjustable []card.HandType            // 可以調整的牌型, nil 表示基準賠率表中所有牌型
+	Strategy   string                     // 計算 RTP 用的策略名稱, 空白為 "optimal"
+}
+
+// Proposal 符合條件的賠率表
+type Proposal struct {
+	Paytable    *card.Paytable            `json:"paytable"`
+	Report      *RTPReport                `json:"report"`
+	Sensitivity map[card.HandType]float64 `json:"sensitivity"` // 各牌型賠率 +1 時 RTP 的變化量
+}
+
+// 每個候選賠率表最多修正幾次
+const maxOptimizeSteps = 8
+
+// OptimizePaytable 以 base 為基準, 逐一調整單一牌型或等比例調整所有可調牌型, 找出符合目標 RTP 的整數賠率表
+//
+// 每個候選都會以策略重新計算完整的 RTP 並檢查出現機率範圍與單調性, 結果依 RTP 誤差由小到大排序
+func (a *Analyzer) OptimizePaytable(config GameConfig, base *card.Paytable, options OptimizeOptions) ([]Proposal, error) {
+	if base == nil {
+		base = a.Paytable
+	}
+	if err := base.Validate(); err != nil {
+		return nil, err
+	}
+	adjustable := options.Adjustable
+	if adjustable == nil {
+		for _, handType := range card.AllHandTypes {
+			if _, ok := base.Pays[handType]; ok {
+				adjustable = append(adjustable, handType)
+			}
+		}
+	}
+
+	baseReport, err := a.evaluatePaytable(config, base, options.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*card.Paytable{}
+	for _, handType := range adjustable {
+		candidate, err := a.searchPaytable(config, base, baseReport, options, func(pays map[card.HandType]int, x float64) {
+			pays[handType] = int(math.Round(x))
+		}, float64(base.Pays[handType]), baseReport.HitFrequency[handType])
+		if err != nil {
+			return nil, err
+		}
+		if candidate != nil {
+			candidate.Version = fmt.Sprintf("%s-%s", base.Version, handTypeName(handType))
+			candidates = append(candidates, candidate)
+		}
+	}
+	// 等比例調整所有可調牌型
+	var scaledHit float64
+	for _, handType := range adjustable {
+		scaledHit += baseReport.HitFrequency[handType] * float64(base.Pays[handType])
+	}
+	candidate, err := a.searchPaytable(config, base, baseReport, options, func(pays map[card.HandType]int, x float64) {
+		for _, handType := range adjustable {
+			pays[handType] = int(math.Round(float64(base.Pays[handType]) * x))
+		}
+	}, 1, scaledHit)
+	if err != nil {
+		return nil, err
+	}
+	if candidate != nil {
+		candidate.Version = base.Version + "-scaled"
+		candidates = append(candidates, candidate)
+	}
+
+	proposals := []Proposal{}
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		key := fmt.Sprint(candidate.Pays)
+		if seen[key] || !satisfiesMonotonic(a.Rules, candidate, options) {
+			continue
+		}
+		seen[key] = true
+		report, err := a.evaluatePaytable(config, candidate, options.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		if !satisfiesRTP(report, options) || !satisfiesHitBounds(report, options) {
+			continue
+		}
+		sensitivity, err := a.PaytableSensitivity(config, candidate, adjustable, options.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, Proposal{
+			Paytable:    candidate,
+			Report:      report,
+			Sensitivity: sensitivity,
+		})
+	}
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return math.Abs(proposals[i].Report.RTP-options.TargetRTP) < math.Abs(proposals[j].Report.RTP-options.TargetRTP)
+	})
+	return proposals, nil
+}
+
+// PaytableSensitivity 各牌型賠率 +1 時重新計算的 RTP 變化量, 策略會跟著賠率表重新計算
+func (a *Analyzer) PaytableSensitivity(config GameConfig, paytable *card.Paytable, handTypes []card.HandType, strategy string) (map[card.HandType]float64, error) {
+	report, err := a.evaluatePaytable(config, paytable, strategy)
+	if err != nil {
+		return nil, err
+	}
+	sensitivity := make(map[card.HandType]float64, len(handTypes))
+	for _, handType := range handTypes {
+		adjusted := copyPaytable(paytable)
+		adjusted.Pays[handType]++
+		adjustedReport, err := a.evaluatePaytable(config, adjusted, strategy)
+		if err != nil {
+			return nil, err
+		}
+		sensitivity[handType] = adjustedReport.RTP - report.RTP
+	}
+	return sensitivity, nil
+}
+
+// 以 apply(x) 產生賠率表, 依 RTP 對 x 的斜率逐步修正 x, 回傳 RTP 誤差最小的賠率表; hit 為 x 每增加1時期望拿回點數的估計增加量
+func (a *Analyzer) searchPaytable(config GameConfig, base *card.Paytable, baseReport *RTPReport, options OptimizeOptions,
+	apply func(pays map[card.HandType]int, x float64), x float64, hit float64) (*card.Paytable, error) {
+	if hit <= 0 || baseReport.ExpectedCost <= 0 {
+		return nil, nil
+	}
+	slope := hit / baseReport.ExpectedCost
+	rtp := baseReport.RTP
+	var best *card.Paytable
+	bestErr := math.Inf(1)
+	lastKey := fmt.Sprint(base.Pays)
+	for step := 0; step < maxOptimizeSteps; step++ {
+		next := x + (options.TargetRTP-rtp)/slope
+		candidate := copyPaytable(base)
+		apply(candidate.Pays, next)
+		if !clampPays(candidate, options.MaxPay) {
+			return best, nil
+		}
+		// 四捨五入後跟上一步相同就不用再算
+		key := fmt.Sprint(candidate.Pays)
+		if key == lastKey {
+			break
+		}
+		lastKey = key
+		report, err := a.evaluatePaytable(config, candidate, options.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		if errRTP := math.Abs(report.RTP - options.TargetRTP); errRTP < bestErr {
+			best, bestErr = candidate, errRTP
+		}
+		if math.Abs(report.RTP-options.TargetRTP) <= options.Tolerance || next == x {
+			break
+		}
+		if report.RTP != rtp {
+			slope = (report.RTP - rtp) / (next - x)
+		}
+		x, rtp = next, report.RTP
+	}
+	return best, nil
+}
+
+// 賠率限制在 0~maxPay 之間, 全部為0時不是合法的賠率表
+func clampPays(paytable *card.Paytable, maxPay int) bool {
+	total := 0
+	for handType, pay := range paytable.Pays {
+		if pay < 0 {
+			pay = 0
+		}
+		if maxPay > 0 && pay > maxPay {
+			pay = maxPay
+		}
+		paytable.Pays[handType] = pay
+		total += pay
+	}
+	return total > 0
+}
+
+func (a *Analyzer) evaluatePaytable(config GameConfig, paytable *card.Paytable, strategyName string) (*RTPReport, error) {
+	if strategyName == "" {
+		strategyName = "optimal"
+	}
+	analyzer := NewAnalyzer(a.Rules, paytable)
+	strategy, err := analyzer.NewStrategy(strategyName, config)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.RTP(config, strategy)
+}
+
+func copyPaytable(paytable *card.Paytable) *card.Paytable {
+	pays := make(map[card.HandType]int, len(paytable.Pays))
+	for handType, pay := range paytable.Pays {
+		pays[handType] = pay
+	}
+	return &card.Paytable{
+		Version: paytable.Version,
+		Mode:    paytable.Mode,
+		Pays:    pays,
+		Stake:   paytable.Stake,
+	}
+}
+
+func satisfiesRTP(report *RTPReport, options OptimizeOptions) bool {
+	return math.Abs(report.RTP-options.TargetRTP) <= options.Tolerance
+}
+
+func satisfiesHitBounds(report *RTPReport, options OptimizeOptions) bool {
+	for handType, bound := range options.HitBounds {
+		hit := report.HitFrequency[handType]
+		if hit < bound.Min || (bound.Max > 0 && hit > bound.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+// 賠率表中有列出且規則下可能出現的牌型, 依大小排列後賠率不可遞減
+func satisfiesMonotonic(rules *card.Rules, paytable *card.Paytable, options OptimizeOptions) bool {
+	if !options.Monotonic {
+		return true
+	}
+	last := 0
+	for _, handType := range card.AllHandTypes {
+		if _, ok := paytable.Pays[handType]; !ok || !isPossible(rules, handType) {
+			continue
+		}
+		pay := paytable.Payout(handType)
+		if pay < last {
+			return false
+		}
+		last = pay
+	}
+	return true
+}
+
+// 停用的牌型與沒有百搭、只有1副牌時的五條不會出現
+func isPossible(rules *card.Rules, handType card.HandType) bool {
+	if !rules.IsEnabled(handType) {
+		return false
+	}
+	if handType == card.FiveOfAKind {
+		return rules.Jokers > 0 || len(rules.WildNumbers) > 0 || rules.Deck.Copies > 1
+	}
+	return true
+}
+
+func handTypeName(handType card.HandType) string {
+	name, _ := handType.MarshalText()
+	return string(name)
+}
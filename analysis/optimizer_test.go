@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"math"
+	"math-discard-card/card"
+	"reflect"
+	"testing"
+)
+
+func TestOptimizePaytable(t *testing.T) {
+	// 2~4 梅花、紅心、黑桃共9張, 基準賠率兩對1、葫蘆10, RTP 2.4875
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	analyzer := NewAnalyzer(rules, paytable)
+	config := GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: 1}
+
+	tests := []struct {
+		name     string
+		options  OptimizeOptions
+		expected []map[card.HandType]int
+	}{
+		{"Scaled", OptimizeOptions{TargetRTP: 0.95, Tolerance: 0.05, Monotonic: true}, []map[card.HandType]int{
+			{card.TwoPair: 0, card.FullHouse: 4},
+		}},
+		{"FullHouseOnly", OptimizeOptions{TargetRTP: 1.5, Tolerance: 0.05, Monotonic: true}, []map[card.HandType]int{
+			{card.TwoPair: 1, card.FullHouse: 6},
+		}},
+		{"MaxPay", OptimizeOptions{TargetRTP: 2, Tolerance: 0.05, MaxPay: 5}, nil},
+		{"HitBounds", OptimizeOptions{TargetRTP: 1.5, Tolerance: 0.05, HitBounds: map[card.HandType]HitBound{card.FullHouse: {Max: 0.5}}}, nil},
+	}
+
+	for _, tt := range tests {
+		proposals, err := analyzer.OptimizePaytable(config, nil, tt.options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(proposals) != len(tt.expected) {
+			t.Fatalf("%s: expected %d proposals, got %d", tt.name, len(tt.expected), len(proposals))
+		}
+		for i, proposal := range proposals {
+			if !reflect.DeepEqual(proposal.Paytable.Pays, tt.expected[i]) {
+				t.Errorf("%s: expected pays %v, got %v", tt.name, tt.expected[i], proposal.Paytable.Pays)
+			}
+			if math.Abs(proposal.Report.RTP-tt.options.TargetRTP) > tt.options.Tolerance {
+				t.Errorf("%s: RTP %f out of tolerance", tt.name, proposal.Report.RTP)
+			}
+			if len(proposal.Sensitivity) != 2 || proposal.Sensitivity[card.FullHouse] <= 0 {
+				t.Errorf("%s: unexpected sensitivity %v", tt.name, proposal.Sensitivity)
+			}
+		}
+	}
+}
+
+func TestPaytableSensitivity(t *testing.T) {
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	analyzer := NewAnalyzer(rules, paytable)
+	config := GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: 1}
+
+	sensitivity, err := analyzer.PaytableSensitivity(config, paytable, []card.HandType{card.FullHouse}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 葫蘆 10 -> 11 不影響策略, RTP 增加 葫蘆機率 / 期望花費
+	if expected := 0.6785714285714285 / 2.857142857142856; math.Abs(sensitivity[card.FullHouse]-expected) > 1e-9 {
+		t.Errorf("expected sensitivity %f, got %f", expected, sensitivity[card.FullHouse])
+	}
+}
+
+func TestSatisfiesMonotonic(t *testing.T) {
+	options := OptimizeOptions{Monotonic: true}
+	if !satisfiesMonotonic(card.StandardRules, card.DefaultPaytable(), options) {
+		t.Errorf("expected default paytable to be monotonic, five of a kind is impossible in standard rules")
+	}
+	if !satisfiesMonotonic(card.DeucesWildRules, card.DefaultPaytable(), options) {
+		t.Errorf("expected default paytable to be monotonic with deuces wild")
+	}
+	paytable := &card.Paytable{Version: "test", Pays: map[card.HandType]int{card.Pair: 5, card.Flush: 3}}
+	if satisfiesMonotonic(card.StandardRules, paytable, options) {
+		t.Errorf("expected pair over flush not to be monotonic")
+	}
+}
@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"context"
+	"math-discard-card/card"
+	"math-discard-card/i18n"
+	"math-discard-card/utility"
+	"math/big"
+)
+
+// Analyzer 依玩法規則與賠率表計算換牌後的各種結果
+type Analyzer struct {
+	Rules    *card.Rules
+	Paytable *card.Paytable
+	Engine   *Engine // 平行列舉的設定, nil 時在目前的 goroutine 依序計算
+}
+
+// 同樣規則與賠率表但依序計算的分析器, 給已經在 worker 中執行的計算使用, 避免再建立一層 worker
+func (a *Analyzer) serial() *Analyzer {
+	return &Analyzer{Rules: a.Rules, Paytable: a.Paytable}
+}
+
+// 以 config 的開局花費為押注的分析器, PayTo 賠率表才會退還正確的押注
+func (a *Analyzer) forGame(config GameConfig) *Analyzer {
+	analyzer := *a
+	analyzer.Paytable = a.Paytable.WithStake(config.GameCost)
+	return &analyzer
+}
+
+// NewAnalyzer 建立分析器, rules、paytable 為 nil 時使用標準規則與預設賠率表
+func NewAnalyzer(rules *card.Rules, paytable *card.Paytable) *Analyzer {
+	if rules == nil {
+		rules = card.StandardRules
+	}
+	if paytable == nil {
+		paytable = card.DefaultPaytable()
+	}
+	return &Analyzer{
+		Rules:    rules,
+		Paytable: paytable,
+	}
+}
+
+// Outcomes 換牌後所有可能抽法的牌型分布, 每種抽法只算入最大的牌型
+type Outcomes struct {
+	Total  int64                   `json:"total"`  // 總抽法數
+	Counts map[card.HandType]int64 `json:"counts"` // 各牌型的抽法數, 包含所有牌型(沒出現的為0)
+}
+
+// Probability 某個牌型的出現機率
+func (o *Outcomes) Probability(handType card.HandType) float64 {
+	if o.Total == 0 {
+		return 0
+	}
+	return float64(o.Counts[handType]) / float64(o.Total)
+}
+
+// Probabilities 所有牌型的出現機率
+func (o *Outcomes) Probabilities() map[card.HandType]float64 {
+	probabilities := make(map[card.HandType]float64, len(o.Counts))
+	for handType := range o.Counts {
+		probabilities[handType] = o.Probability(handType)
+	}
+	return probabilities
+}
+
+// ExactProbability 某個牌型出現機率的精確分數, Total 為0時為0
+func (o *Outcomes) ExactProbability(handType card.HandType) *big.Rat {
+	if o.Total == 0 {
+		return new(big.Rat)
+	}
+	return big.NewRat(o.Counts[handType], o.Total)
+}
+
+// ExactProbabilities 所有牌型出現機率的精確分數, 加總剛好為1
+func (o *Outcomes) ExactProbabilities() map[card.HandType]*big.Rat {
+	probabilities := make(map[card.HandType]*big.Rat, len(o.Counts))
+	for handType := range o.Counts {
+		probabilities[handType] = o.ExactProbability(handType)
+	}
+	return probabilities
+}
+
+// RoundedProbabilities 所有牌型的機率以最大餘數法捨入到 decimalPlaces 位小數, 捨入後加總仍剛好為1
+func (o *Outcomes) RoundedProbabilities(decimalPlaces int) map[card.HandType]*big.Rat {
+	exact := make([]*big.Rat, len(card.AllHandTypes))
+	for i, handType := range card.AllHandTypes {
+		exact[i] = o.ExactProbability(handType)
+	}
+	rounded := utility.RoundRats(exact, decimalPlaces)
+	probabilities := make(map[card.HandType]*big.Rat, len(card.AllHandTypes))
+	for i, handType := range card.AllHandTypes {
+		probabilities[handType] = rounded[i]
+	}
+	return probabilities
+}
+
+// DiscardOutcomes 以標準規則計算換牌後的牌型分布, 見 Analyzer.DiscardOutcomes
+func DiscardOutcomes(hand []*card.Card, discardIdxs []int, deadCards []*card.Card) (*Outcomes, error) {
+	return NewAnalyzer(nil, nil).DiscardOutcomes(hand, discardIdxs, deadCards)
+}
+
+// DiscardOutcomes 換掉手牌中 discardIdxs 位置的牌後, 列舉剩餘牌堆所有可能的抽法並統計牌型
+//
+// 剩餘牌堆為規則的整副牌扣掉手牌(含要換掉的牌)與死牌, 多副牌時依牌面逐張扣除
+func (a *Analyzer) DiscardOutcomes(hand []*card.Card, discardIdxs []int, deadCards []*card.Card) (*Outcomes, error) {
+	return a.DiscardOutcomesContext(context.Background(), hand, discardIdxs, deadCards)
+}
+
+// DiscardOutcomesContext 同 DiscardOutcomes, 可以用 ctx 取消, 依 Analyzer.Engine 平行列舉
+func (a *Analyzer) DiscardOutcomesContext(ctx context.Context, hand []*card.Card, discardIdxs []int, deadCards []*card.Card) (*Outcomes, error) {
+	kept, err := keptCards(hand, discardIdxs)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := a.remainingDeck(hand, deadCards)
+	if err != nil {
+		return nil, err
+	}
+	draw := len(hand) - len(kept)
+	if draw > len(remaining) {
+		return nil, i18n.Errorf("error.not_enough_cards", len(remaining), draw)
+	}
+
+	outcomes := &Outcomes{Counts: make(map[card.HandType]int64, len(card.AllHandTypes))}
+	for _, handType := range card.AllHandTypes {
+		outcomes.Counts[handType] = 0
+	}
+	total := utility.Binomial(len(remaining), draw)
+	err = RunParallel(ctx, a.Engine, total, defaultChunkSize, func(ctx context.Context, start, end int64) ([card.RoyalFlush + 1]int64, error) {
+		var counts [card.RoyalFlush + 1]int64
+		cards := append(make([]*card.Card, 0, len(hand)), kept...)
+		cards = cards[:len(hand)]
+		err := utility.NewCombinations(remaining, draw).EachRange(start, end, func(drawn []*card.Card) bool {
+			copy(cards[len(kept):], drawn)
+			counts[a.Rules.GetHandType(cards)]++
+			return true
+		})
+		return counts, err
+	}, func(counts [card.RoyalFlush + 1]int64) {
+		for handType, count := range counts {
+			outcomes.Counts[card.HandType(handType)] += count
+			outcomes.Total += count
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+// 檢查換牌位置並回傳留下的牌
+func keptCards(hand []*card.Card, discardIdxs []int) ([]*card.Card, error) {
+	if len(hand) == 0 {
+		return nil, i18n.Errorf("error.empty_hand")
+	}
+	discard := make([]bool, len(hand))
+	for _, idx := range discardIdxs {
+		if idx < 0 || idx >= len(hand) {
+			return nil, i18n.Errorf("error.discard_range", idx)
+		}
+		if discard[idx] {
+			return nil, i18n.Errorf("error.discard_duplicate", idx)
+		}
+		discard[idx] = true
+	}
+	kept := make([]*card.Card, 0, len(hand)-len(discardIdxs))
+	for i, c := range hand {
+		if !discard[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept, nil
+}
+
+// 規則的整副牌扣掉手牌與死牌, 依牌面比對, 每張只扣一張
+func (a *Analyzer) remainingDeck(hand []*card.Card, deadCards []*card.Card) ([]*card.Card, error) {
+	removed := make(map[int]int)
+	for _, c := range hand {
+		removed[c.Idx]++
+	}
+	for _, c := range deadCards {
+		removed[c.Idx]++
+	}
+	remaining := []*card.Card{}
+	for _, c := range a.Rules.NewDeck() {
+		if removed[c.Idx] > 0 {
+			removed[c.Idx]--
+			continue
+		}
+		remaining = append(remaining, c)
+	}
+	for idx, count := range removed {
+		if count > 0 {
+			return nil, i18n.Errorf("error.dead_card", idx)
+		}
+	}
+	return remaining, nil
+}
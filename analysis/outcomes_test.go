@@ -0,0 +1,156 @@
+package analysis
+
+import (
+	"math"
+	"math-discard-card/card"
+	"math/big"
+	"testing"
+)
+
+func TestDiscardOutcomes(t *testing.T) {
+	tests := []struct {
+		name     string
+		hand     string
+		discard  []int
+		dead     string
+		total    int64
+		expected map[card.HandType]int64
+	}{
+		{"HoldAll", "As Ad Ah Ac 2d", nil, "", 1, map[card.HandType]int64{card.FourOfAKind: 1}},
+		{"QuadsDrawKicker", "As Ad Ah Ac 2d", []int{4}, "", 47, map[card.HandType]int64{card.FourOfAKind: 47}},
+		{"FourToRoyal", "Ah Kh Qh Jh 2c", []int{4}, "", 47, map[card.HandType]int64{
+			card.RoyalFlush: 1, card.Flush: 8, card.Straight: 3, card.Pair: 12, card.HighCard: 23,
+		}},
+		{"FourToRoyalDeadTen", "Ah Kh Qh Jh 2c", []int{4}, "Th Ts", 45, map[card.HandType]int64{
+			card.Flush: 8, card.Straight: 2, card.Pair: 12, card.HighCard: 23,
+		}},
+		// 換2張: C(47,2) = 1081
+		{"TripsDrawTwo", "9s 9d 9h 4c 2d", []int{3, 4}, "", 1081, map[card.HandType]int64{
+			card.FourOfAKind: 46, card.FullHouse: 66, card.ThreeOfAKind: 969,
+		}},
+	}
+
+	for _, tt := range tests {
+		dead := []*card.Card{}
+		if tt.dead != "" {
+			dead = card.MustParseHand(tt.dead)
+		}
+		outcomes, err := DiscardOutcomes(card.MustParseHand(tt.hand), tt.discard, dead)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if outcomes.Total != tt.total {
+			t.Errorf("%s: expected total %d, got %d", tt.name, tt.total, outcomes.Total)
+		}
+		var sum int64
+		for _, handType := range card.AllHandTypes {
+			if outcomes.Counts[handType] != tt.expected[handType] {
+				t.Errorf("%s: expected %s count %d, got %d", tt.name, handType.ToString(), tt.expected[handType], outcomes.Counts[handType])
+			}
+			sum += outcomes.Counts[handType]
+		}
+		if sum != outcomes.Total {
+			t.Errorf("%s: counts sum %d != total %d", tt.name, sum, outcomes.Total)
+		}
+	}
+}
+
+func TestDiscardOutcomesProbability(t *testing.T) {
+	outcomes, err := DiscardOutcomes(card.MustParseHand("Ah Kh Qh Jh 2c"), []int{4}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := outcomes.Probability(card.RoyalFlush); math.Abs(p-1.0/47) > 1e-12 {
+		t.Errorf("expected royal flush probability 1/47, got %f", p)
+	}
+	var total float64
+	for _, p := range outcomes.Probabilities() {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-12 {
+		t.Errorf("expected probabilities to sum to 1, got %f", total)
+	}
+}
+
+func TestDiscardOutcomesExactProbability(t *testing.T) {
+	outcomes, err := DiscardOutcomes(card.MustParseHand("Ah Kh Qh Jh 2c"), []int{4}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := outcomes.ExactProbability(card.Flush); p.Cmp(big.NewRat(8, 47)) != 0 {
+		t.Errorf("expected flush probability 8/47, got %v", p)
+	}
+	total := new(big.Rat)
+	for _, p := range outcomes.ExactProbabilities() {
+		total.Add(total, p)
+	}
+	if total.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected exact probabilities to sum to 1, got %v", total)
+	}
+
+	// 1/47、8/47、3/47、12/47、23/47 捨入到2位: 0.02、0.17、0.06、0.26、0.49
+	expected := map[card.HandType]string{
+		card.RoyalFlush: "0.02", card.Flush: "0.17", card.Straight: "0.06", card.Pair: "0.26", card.HighCard: "0.49",
+	}
+	rounded := outcomes.RoundedProbabilities(2)
+	total = new(big.Rat)
+	for _, handType := range card.AllHandTypes {
+		want, ok := expected[handType]
+		if !ok {
+			want = "0.00"
+		}
+		if got := rounded[handType].FloatString(2); got != want {
+			t.Errorf("expected rounded %s probability %s, got %s", handType.ToString(), want, got)
+		}
+		total.Add(total, rounded[handType])
+	}
+	if total.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected rounded probabilities to sum to 1, got %v", total)
+	}
+}
+
+func TestDiscardOutcomesRules(t *testing.T) {
+	// 百搭2: 留下 A♥K♥Q♥J♥ 換1張, 4張2與同花10都是同花大順
+	analyzer := NewAnalyzer(card.DeucesWildRules, nil)
+	outcomes, err := analyzer.DiscardOutcomes(card.MustParseHand("Ah Kh Qh Jh 3c"), []int{4}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcomes.Counts[card.RoyalFlush] != 5 {
+		t.Errorf("expected 5 royal flush draws, got %d", outcomes.Counts[card.RoyalFlush])
+	}
+
+	// 2副牌: 手牌扣掉後同牌面還剩1張
+	analyzer = NewAnalyzer(&card.Rules{Deck: card.DoubleDeck}, nil)
+	outcomes, err = analyzer.DiscardOutcomes(card.MustParseHand("As Ad Ah Ac 2d"), []int{4}, card.MustParseHand("Ks"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcomes.Total != 98 || outcomes.Counts[card.FiveOfAKind] != 4 {
+		t.Errorf("expected 4 of 98 five of a kind draws, got %d of %d", outcomes.Counts[card.FiveOfAKind], outcomes.Total)
+	}
+}
+
+func TestDiscardOutcomesErrors(t *testing.T) {
+	hand := card.MustParseHand("As Ad Ah Ac 2d")
+	tests := []struct {
+		name    string
+		discard []int
+		dead    []*card.Card
+	}{
+		{"OutOfRange", []int{5}, nil},
+		{"Negative", []int{-1}, nil},
+		{"Duplicated", []int{1, 1}, nil},
+		{"DeadInHand", []int{4}, card.MustParseHand("As")},
+	}
+	for _, tt := range tests {
+		if _, err := DiscardOutcomes(hand, tt.discard, tt.dead); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+	for _, empty := range [][]*card.Card{nil, {}} {
+		if _, err := DiscardOutcomes(empty, nil, nil); err == nil {
+			t.Errorf("expected an error for an empty hand")
+		}
+	}
+}
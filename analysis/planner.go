@@ -0,0 +1,258 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math-discard-card/card"
+	"math-discard-card/utility"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DiscardCost 換牌花費, 第 n 次(從0開始)換牌花費 Default + n*Add, 與 CardGame.curDiscardCost 相同
+type DiscardCost struct {
+	Default int
+	Add     int
+}
+
+// At 已經換過 discardCount 次時, 下一次換牌的花費
+func (c DiscardCost) At(discardCount int) int {
+	return c.Default + discardCount*c.Add
+}
+
+// State 一局遊戲進行中的狀態
+type State struct {
+	Hand         []*card.Card `json:"hand"`          // 目前的手牌
+	Dead         []*card.Card `json:"dead"`          // 已經不在牌堆中的其他牌(換掉的牌、已知的死牌)
+	DiscardCount int          `json:"discard_count"` // 已經換過幾次牌
+}
+
+// Decision 某個狀態下的最佳選擇
+type Decision struct {
+	Settle      bool     `json:"settle"`       // 直接結算
+	Discard     []int    `json:"discard"`      // 不結算時要換掉的手牌位置
+	Value       float64  `json:"value"`        // 依最佳策略玩下去的期望淨點數(拿回的點數扣掉之後所有換牌花費)
+	SettleValue float64  `json:"settle_value"` // 直接結算拿回的點數
+	ExactValue  *big.Rat `json:"exact_value"`  // Value 的精確值, 策略沒有計算期望值時為 nil
+}
+
+// Planner 以動態規劃計算多次換牌的最佳策略, 每次換牌後都可以選擇結算或花更多點數再換
+//
+// 換掉的牌不會洗回牌堆, 計算量會隨著可換次數快速增加, 標準牌組建議 MaxRounds 設定在1~2;
+// 計算過的狀態會被記錄下來重複使用, 可以同時在多個 goroutine 中使用
+type Planner struct {
+	*Analyzer
+	Cost      DiscardCost
+	MaxRounds int                 // 一局最多換幾次牌, 0 表示換到牌堆不夠為止
+	memo      map[string]*big.Rat // 記錄的值不可修改
+	mutex     sync.Mutex
+}
+
+// NewPlanner 建立多次換牌的分析器, analyzer 為 nil 時使用標準規則與預設賠率表
+func NewPlanner(analyzer *Analyzer, cost DiscardCost, maxRounds int) *Planner {
+	if analyzer == nil {
+		analyzer = NewAnalyzer(nil, nil)
+	}
+	return &Planner{
+		Analyzer:  analyzer,
+		Cost:      cost,
+		MaxRounds: maxRounds,
+		memo:      make(map[string]*big.Rat),
+	}
+}
+
+// 攤平列舉換牌選擇與抽法時每段工作的大小, 之後的狀態遞迴計算, 每個抽法的計算量比單純評估牌型大
+const plannerChunkSize = 1 << 10
+
+// Value 從 state 開始依最佳策略玩下去的期望淨點數, 不含開局花費
+func (p *Planner) Value(state State) (float64, error) {
+	return p.ValueContext(context.Background(), state)
+}
+
+// ValueContext 同 Value, 可以用 ctx 取消, 依 Analyzer.Engine 平行計算
+func (p *Planner) ValueContext(ctx context.Context, state State) (float64, error) {
+	decision, err := p.DecideContext(ctx, state)
+	if err != nil {
+		return 0, err
+	}
+	return decision.Value, nil
+}
+
+// Decide 計算 state 下的最佳選擇: 直接結算, 或是換掉哪幾張牌; 期望值相同時優先結算, 其次換比較少張
+func (p *Planner) Decide(state State) (Decision, error) {
+	return p.DecideContext(context.Background(), state)
+}
+
+// DecideContext 同 Decide, 可以用 ctx 取消
+//
+// state 的所有換牌選擇與抽法攤平成一個範圍, 依 Analyzer.Engine 平行列舉並回報進度;
+// 抽牌之後的狀態在各 worker 中依序計算
+func (p *Planner) DecideContext(ctx context.Context, state State) (Decision, error) {
+	return p.decide(ctx, state, p.Engine)
+}
+
+// 在 engine 上計算 state 的最佳選擇, 遞迴計算之後的狀態時 engine 為 nil
+func (p *Planner) decide(ctx context.Context, state State, engine *Engine) (Decision, error) {
+	settleValue := p.Paytable.Payout(p.Rules.GetHandType(state.Hand))
+	decision := Decision{
+		Settle:      true,
+		Value:       float64(settleValue),
+		SettleValue: float64(settleValue),
+		ExactValue:  big.NewRat(int64(settleValue), 1),
+	}
+	if !p.canDiscard(state.DiscardCount) {
+		return decision, nil
+	}
+	remaining, err := p.remainingDeck(state.Hand, state.Dead)
+	if err != nil {
+		return decision, err
+	}
+
+	subsets := discardSubsets(len(state.Hand), len(remaining))
+	// 每個換牌選擇的抽法在攤平後範圍中的起點
+	offsets := make([]int64, len(subsets)+1)
+	for i, discard := range subsets {
+		offsets[i+1] = offsets[i] + utility.Binomial(len(remaining), len(discard))
+	}
+	sums := make([]*big.Rat, len(subsets))
+	for i := range sums {
+		sums[i] = new(big.Rat)
+	}
+	err = RunParallel(ctx, engine, offsets[len(subsets)], plannerChunkSize, func(ctx context.Context, start, end int64) (planChunk, error) {
+		i := sort.Search(len(subsets), func(i int) bool { return offsets[i+1] > start })
+		chunk := planChunk{first: i}
+		for ; i < len(subsets) && offsets[i] < end; i++ {
+			sum, err := p.drawSum(ctx, state, remaining, subsets[i], max(start, offsets[i])-offsets[i], min(end, offsets[i+1])-offsets[i])
+			if err != nil {
+				return chunk, err
+			}
+			chunk.sums = append(chunk.sums, sum)
+		}
+		return chunk, nil
+	}, func(chunk planChunk) {
+		for j, sum := range chunk.sums {
+			sums[chunk.first+j].Add(sums[chunk.first+j], sum)
+		}
+	})
+	if err != nil {
+		return decision, err
+	}
+
+	cost := big.NewRat(int64(p.Cost.At(state.DiscardCount)), 1)
+	for i, discard := range subsets {
+		value := sums[i].Quo(sums[i], big.NewRat(offsets[i+1]-offsets[i], 1))
+		value.Sub(value, cost)
+		if value.Cmp(decision.ExactValue) > 0 {
+			decision.Settle = false
+			decision.Discard = discard
+			decision.ExactValue = value
+		}
+	}
+	decision.Value = ratFloat(decision.ExactValue)
+	return decision, nil
+}
+
+// 一段攤平範圍的結果, 從第 first 個換牌選擇開始, 各換牌選擇在這段中抽法的淨點數總和
+type planChunk struct {
+	first int
+	sums  []*big.Rat
+}
+
+func (p *Planner) canDiscard(discardCount int) bool {
+	return p.MaxRounds <= 0 || discardCount < p.MaxRounds
+}
+
+// 換掉 discard 位置的牌後, 排名在 [start, end) 的抽法依最佳策略玩下去的淨點數總和(還沒扣除這次換牌花費)
+func (p *Planner) drawSum(ctx context.Context, state State, remaining []*card.Card, discard []int, start, end int64) (*big.Rat, error) {
+	kept, err := keptCards(state.Hand, discard)
+	if err != nil {
+		return nil, err
+	}
+	dead := append([]*card.Card{}, state.Dead...)
+	for _, idx := range discard {
+		dead = append(dead, state.Hand[idx])
+	}
+	next := State{
+		Hand:         append(kept, make([]*card.Card, len(discard))...),
+		Dead:         dead,
+		DiscardCount: state.DiscardCount + 1,
+	}
+
+	// 不能再換牌時的結算點數是整數, 先以整數累加
+	sum := new(big.Rat)
+	var payouts int64
+	eachErr := utility.NewCombinations(remaining, len(discard)).EachRange(start, end, func(drawn []*card.Card) bool {
+		copy(next.Hand[len(kept):], drawn)
+		if !p.canDiscard(next.DiscardCount) {
+			payouts += int64(p.Paytable.Payout(p.Rules.GetHandType(next.Hand)))
+			return true
+		}
+		var value *big.Rat
+		value, err = p.value(ctx, next)
+		if err == nil {
+			sum.Add(sum, value)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if eachErr != nil {
+		return nil, eachErr
+	}
+	return sum.Add(sum, big.NewRat(payouts, 1)), nil
+}
+
+// 還能換牌的狀態依最佳策略玩下去的精確期望值, 有記錄時直接使用, 手牌與死牌的順序不影響結果
+func (p *Planner) value(ctx context.Context, state State) (*big.Rat, error) {
+	key := stateKey(state)
+	p.mutex.Lock()
+	value, ok := p.memo[key]
+	p.mutex.Unlock()
+	if ok {
+		return value, nil
+	}
+	decision, err := p.decide(ctx, state, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.memo[key] = decision.ExactValue
+	p.mutex.Unlock()
+	return decision.ExactValue, nil
+}
+
+func stateKey(state State) string {
+	var key strings.Builder
+	writeCards := func(cards []*card.Card) {
+		idxs := make([]int, len(cards))
+		for i, c := range cards {
+			idxs[i] = c.Idx
+		}
+		sort.Ints(idxs)
+		for _, idx := range idxs {
+			key.WriteByte(byte(idx))
+		}
+	}
+	writeCards(state.Hand)
+	key.WriteByte(0)
+	writeCards(state.Dead)
+	key.WriteByte(0)
+	fmt.Fprint(&key, state.DiscardCount)
+	return key.String()
+}
+
+// 所有非空的換牌位置組合, 只保留牌堆夠抽的, 換比較少張的排前面
+func discardSubsets(handSize, remaining int) [][]int {
+	subsets := [][]int{}
+	for k := 1; k <= handSize && k <= remaining; k++ {
+		// 只需要位置, 元素本身沒有用到
+		combinations := utility.NewCombinations(make([]struct{}, handSize), k)
+		for combinations.Next() {
+			subsets = append(subsets, append([]int{}, combinations.Indices()...))
+		}
+	}
+	return subsets
+}
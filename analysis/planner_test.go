@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math-discard-card/card"
+	"reflect"
+	"testing"
+)
+
+// 2~4 梅花、紅心、黑桃共9張, 沒有順子也湊不出同花, 只有葫蘆派彩
+func smallPlanner(maxRounds int) *Planner {
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.FullHouse: 10}}
+	return NewPlanner(NewAnalyzer(rules, paytable), DiscardCost{Default: 1, Add: 1}, maxRounds)
+}
+
+func TestPlannerDecide(t *testing.T) {
+	hand := card.MustParseHand("2s 2h 3s 3h 4s")
+	tests := []struct {
+		name      string
+		maxRounds int
+		expected  float64
+	}{
+		// 換掉4♠: 1/2 機率抽到2或3成為葫蘆
+		{"OneRound", 1, 10.0/2 - 1},
+		// 換掉兩張2: 1/2 機率葫蘆, 沒中時再花2點換一定能湊成葫蘆
+		{"TwoRounds", 2, 10.0/2 + (10.0-2)/2 - 1},
+		{"Unlimited", 0, 10.0/2 + (10.0-2)/2 - 1},
+	}
+
+	for _, tt := range tests {
+		decision, err := smallPlanner(tt.maxRounds).Decide(State{Hand: hand})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if decision.Settle {
+			t.Errorf("%s: expected to discard", tt.name)
+		}
+		if math.Abs(decision.Value-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected value %f, got %f", tt.name, tt.expected, decision.Value)
+		}
+		if exact, _ := decision.ExactValue.Float64(); exact != tt.expected || !decision.ExactValue.IsInt() {
+			t.Errorf("%s: expected exact value %f, got %s", tt.name, tt.expected, decision.ExactValue.RatString())
+		}
+		if decision.SettleValue != 0 {
+			t.Errorf("%s: expected settle value 0, got %f", tt.name, decision.SettleValue)
+		}
+	}
+}
+
+func TestPlannerSettle(t *testing.T) {
+	planner := smallPlanner(2)
+	// 已經是葫蘆
+	decision, err := planner.Decide(State{Hand: card.MustParseHand("2s 2h 2c 3s 3h")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decision.Settle || decision.Value != 10 {
+		t.Errorf("expected to settle for 10, got settle=%v value=%f", decision.Settle, decision.Value)
+	}
+	// 已經換到上限
+	decision, err = planner.Decide(State{Hand: card.MustParseHand("2s 2h 3s 3h 4s"), DiscardCount: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decision.Settle {
+		t.Errorf("expected to settle after max rounds, got discard %v", decision.Discard)
+	}
+}
+
+func TestPlannerMatchesSolveHold(t *testing.T) {
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{1, 9, 10, 11, 12, 13}, Suits: []card.SuitType{card.Hearts, card.Spades}}}
+	analyzer := NewAnalyzer(rules, nil)
+	hand := card.MustParseHand("As Ks Qh 9h 9s")
+	results, err := analyzer.SolveHold(hand, 3, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	value, err := NewPlanner(analyzer, DiscardCost{Default: 3, Add: 2}, 1).Value(State{Hand: hand})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(value-results[0].NetEV) > 1e-9 {
+		t.Errorf("expected one round value %f to match solver %f", value, results[0].NetEV)
+	}
+	more, err := NewPlanner(analyzer, DiscardCost{Default: 3, Add: 2}, 2).Value(State{Hand: hand})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if more < value-1e-9 {
+		t.Errorf("expected two rounds value %f >= one round %f", more, value)
+	}
+}
+
+func TestPlannerDecideParallel(t *testing.T) {
+	analyzer := NewAnalyzer(nil, nil)
+	hand := card.MustParseHand("Ah Kh Qh 9c 2d")
+	state := State{Hand: hand, Dead: card.MustParseHand("Jh Th 3c 4c 5c 6c 7c 8c Tc Jc Qc Kc Ac 2c 3d 4d 5d 6d 7d 8d 9d Td Jd Qd Kd Ad")}
+	expected, err := NewPlanner(analyzer, DiscardCost{Default: 1, Add: 1}, 1).Decide(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parallel := NewAnalyzer(nil, nil)
+	var last, total int64
+	parallel.Engine = &Engine{Workers: 4, ChunkSize: 64, Progress: func(done, all int64) {
+		last, total = done, all
+	}}
+	got, err := NewPlanner(parallel, DiscardCost{Default: 1, Add: 1}, 1).DecideContext(context.Background(), state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Settle != expected.Settle || !reflect.DeepEqual(got.Discard, expected.Discard) || math.Abs(got.Value-expected.Value) > 1e-9 {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+	// 21 張剩餘牌, 所有換牌選擇的抽法總數: C(5,k)*C(21,k), k=1~5
+	if expectedTotal := int64(5*21 + 10*210 + 10*1330 + 5*5985 + 20349); total != expectedTotal || last != total {
+		t.Errorf("expected progress to reach %d, got %d/%d", expectedTotal, last, total)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewPlanner(parallel, DiscardCost{Default: 1, Add: 1}, 1).DecideContext(ctx, state); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"math-discard-card/card"
+	"math-discard-card/i18n"
+	"math-discard-card/utility"
+	"math/big"
+	"math/bits"
+)
+
+// GameConfig 一局遊戲的花費設定, 對應 game.Config 的設定
+type GameConfig struct {
+	GameCost           int `json:"game_cost"`            // 開局花費
+	DefaultDiscardCost int `json:"default_discard_cost"` // 第一次換牌的花費
+	DiscardAddCost     int `json:"discard_add_cost"`     // 每多換一次增加的花費
+	HandSize           int `json:"hand_size"`            // 手牌張數, 0 視為5張; 超過5張時以最大的5張組合結算
+	MaxRounds          int `json:"max_rounds"`           // 一局最多換幾次牌, 0 表示換到牌堆不夠為止
+}
+
+// DiscardCost 換牌花費設定
+func (c GameConfig) DiscardCost() DiscardCost {
+	return DiscardCost{Default: c.DefaultDiscardCost, Add: c.DiscardAddCost}
+}
+
+func (c GameConfig) handSize() int {
+	if c.HandSize <= 0 {
+		return 5
+	}
+	return c.HandSize
+}
+
+// 檢查手牌張數: 至少要有5張才能組成牌型, 也不能超過牌組張數
+func (c GameConfig) checkHandSize(deckSize int) (int, error) {
+	handSize := c.handSize()
+	if handSize < 5 {
+		return 0, i18n.Errorf("error.min_hand_size", handSize)
+	}
+	if handSize > deckSize {
+		return 0, i18n.Errorf("error.deck_too_small", deckSize, handSize)
+	}
+	return handSize, nil
+}
+
+// Strategy 決定每個狀態要結算還是換牌
+type Strategy interface {
+	Name() string
+	Decide(state State) (Decision, error)
+}
+
+// NeverDiscard 拿到手牌直接結算的策略
+type NeverDiscard struct{}
+
+func (NeverDiscard) Name() string {
+	return "never"
+}
+
+func (NeverDiscard) Decide(state State) (Decision, error) {
+	return Decision{Settle: true}, nil
+}
+
+// Name 策略名稱, Planner 為最佳策略
+func (p *Planner) Name() string {
+	return "optimal"
+}
+
+// StrategyNames 可以用名稱指定的策略
+var StrategyNames = []string{"optimal", "never"}
+
+// NewStrategy 依名稱建立策略: "optimal" 依設定的換牌花費與次數上限取最佳策略, "never" 從不換牌
+//
+// 策略會在 RTP、模擬的 worker 中使用, 所以 optimal 本身不再平行計算
+func (a *Analyzer) NewStrategy(name string, config GameConfig) (Strategy, error) {
+	switch name {
+	case "optimal":
+		return NewPlanner(a.forGame(config).serial(), config.DiscardCost(), config.MaxRounds), nil
+	case "never":
+		return NeverDiscard{}, nil
+	default:
+		return nil, i18n.Errorf("error.strategy", name)
+	}
+}
+
+// RTPReport 整個遊戲的理論回報
+//
+// 列舉時以分數累加, Exact 開頭的欄位為精確值, 其餘 float64 欄位由精確值轉換
+type RTPReport struct {
+	Strategy          string                     `json:"strategy"`            // 使用的策略
+	StartingHands     int64                      `json:"starting_hands"`      // 所有可能的起手牌組合數
+	ExpectedPayout    float64                    `json:"expected_payout"`     // 每局期望拿回的點數
+	ExpectedCost      float64                    `json:"expected_cost"`       // 每局期望花費(開局 + 換牌)
+	RTP               float64                    `json:"rtp"`                 // 期望拿回 / 期望花費
+	HouseEdge         float64                    `json:"house_edge"`          // 1 - RTP
+	HitFrequency      map[card.HandType]float64  `json:"hit_frequency"`       // 結算時各牌型的機率
+	StdDev            float64                    `json:"std_dev"`             // 每局淨輸贏點數的標準差
+	ExactPayout       *big.Rat                   `json:"exact_payout"`        // 期望拿回點數的精確值
+	ExactCost         *big.Rat                   `json:"exact_cost"`          // 期望花費的精確值
+	ExactRTP          *big.Rat                   `json:"exact_rtp"`           // RTP 的精確值, 期望花費為0時為 nil
+	ExactHouseEdge    *big.Rat                   `json:"exact_house_edge"`    // 莊家優勢的精確值, 期望花費為0時為 nil
+	ExactHitFrequency map[card.HandType]*big.Rat `json:"exact_hit_frequency"` // 各牌型機率的精確值, 加總為1
+}
+
+// 結算點數都是整數, 同一個狀態底下結算的抽法先以整數累加, 最後才換成分數
+type rtpIntSums struct {
+	payout  int64
+	cost    int64
+	net     int64
+	netSqHi uint64 // 淨點數平方和的高 64 bits, 避免溢位
+	netSqLo uint64
+	hits    [card.RoyalFlush + 1]int64
+}
+
+// 加入 weight 個結算結果, spent 為開局以外的換牌花費
+func (s *rtpIntSums) add(handType card.HandType, payout, spent, gameCost, weight int64) {
+	net := payout - spent - gameCost
+	s.hits[handType] += weight
+	s.payout += weight * payout
+	s.cost += weight * spent
+	s.net += weight * net
+	hi, lo := bits.Mul64(uint64(net*net), uint64(weight))
+	var carry uint64
+	s.netSqLo, carry = bits.Add64(s.netSqLo, lo, 0)
+	s.netSqHi += hi + carry
+}
+
+// 以分數累加的期望值(乘上到達機率)
+type rtpAccumulator struct {
+	payout big.Rat
+	cost   big.Rat
+	net    big.Rat
+	netSq  big.Rat
+	hits   [card.RoyalFlush + 1]big.Rat
+}
+
+func (acc *rtpAccumulator) merge(other *rtpAccumulator) {
+	acc.payout.Add(&acc.payout, &other.payout)
+	acc.cost.Add(&acc.cost, &other.cost)
+	acc.net.Add(&acc.net, &other.net)
+	acc.netSq.Add(&acc.netSq, &other.netSq)
+	for handType := range other.hits {
+		acc.hits[handType].Add(&acc.hits[handType], &other.hits[handType])
+	}
+}
+
+// 加入整數的加總, 乘上 weight
+func (acc *rtpAccumulator) addInts(sums *rtpIntSums, weight *big.Rat) {
+	addScaled := func(dst *big.Rat, value *big.Int) {
+		if value.Sign() != 0 {
+			dst.Add(dst, new(big.Rat).Mul(new(big.Rat).SetInt(value), weight))
+		}
+	}
+	addScaled(&acc.payout, big.NewInt(sums.payout))
+	addScaled(&acc.cost, big.NewInt(sums.cost))
+	addScaled(&acc.net, big.NewInt(sums.net))
+	netSq := new(big.Int).Lsh(new(big.Int).SetUint64(sums.netSqHi), 64)
+	addScaled(&acc.netSq, netSq.Or(netSq, new(big.Int).SetUint64(sums.netSqLo)))
+	for handType, hit := range sums.hits {
+		addScaled(&acc.hits[handType], big.NewInt(hit))
+	}
+}
+
+// 所有欄位乘上 weight
+func (acc *rtpAccumulator) scale(weight *big.Rat) {
+	acc.payout.Mul(&acc.payout, weight)
+	acc.cost.Mul(&acc.cost, weight)
+	acc.net.Mul(&acc.net, weight)
+	acc.netSq.Mul(&acc.netSq, weight)
+	for handType := range acc.hits {
+		acc.hits[handType].Mul(&acc.hits[handType], weight)
+	}
+}
+
+// 列舉時共用的設定
+type rtpRun struct {
+	config   GameConfig
+	strategy Strategy
+}
+
+// 每段平行工作處理幾組起手牌
+const rtpChunkSize = 64
+
+// RTP 列舉所有起手牌與之後依策略換牌的所有抽法, 計算理論回報
+//
+// 花色互換後相同的起手牌只會計算一次; 計算量與策略有關, 標準牌組使用最佳策略時非常耗時
+func (a *Analyzer) RTP(config GameConfig, strategy Strategy) (*RTPReport, error) {
+	return a.RTPContext(context.Background(), config, strategy)
+}
+
+// RTPContext 同 RTP, 可以用 ctx 取消, 依 Analyzer.Engine 把起手牌分給多個 worker 計算, 策略必須可以同時在多個 goroutine 中使用
+//
+// 以分數累加, 結果與 worker 數量、分段方式無關; 起手牌分組與計算兩個階段各自回報進度
+func (a *Analyzer) RTPContext(ctx context.Context, config GameConfig, strategy Strategy) (*RTPReport, error) {
+	a = a.forGame(config)
+	deck := a.Rules.NewDeck()
+	handSize, err := config.checkHandSize(len(deck))
+	if err != nil {
+		return nil, err
+	}
+
+	// 先分組起手牌, 這一段也會回報進度並可以取消
+	classes, err := canonicalClasses(ctx, a.Engine, deck, handSize)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, class := range classes {
+		total += class.count
+	}
+	run := &rtpRun{config: config, strategy: strategy}
+	acc := &rtpAccumulator{}
+	err = RunParallel(ctx, a.Engine, int64(len(classes)), rtpChunkSize, func(ctx context.Context, start, end int64) (*rtpAccumulator, error) {
+		// 起手牌的權重為組合數, 最後再除以總組合數
+		partial := &rtpAccumulator{}
+		var sums rtpIntSums
+		for _, class := range classes[start:end] {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := a.play(run, State{Hand: class.hand}, 0, class.count, &sums, partial); err != nil {
+				return nil, err
+			}
+		}
+		partial.addInts(&sums, big.NewRat(1, 1))
+		return partial, nil
+	}, acc.merge)
+	if err != nil {
+		return nil, err
+	}
+	acc.scale(big.NewRat(1, total))
+	return newRTPReport(strategy.Name(), total, config, acc), nil
+}
+
+// 由期望值建立報表
+func newRTPReport(strategy string, total int64, config GameConfig, acc *rtpAccumulator) *RTPReport {
+	cost := new(big.Rat).Add(&acc.cost, big.NewRat(int64(config.GameCost), 1))
+	variance := new(big.Rat).Sub(&acc.netSq, new(big.Rat).Mul(&acc.net, &acc.net))
+	report := &RTPReport{
+		Strategy:          strategy,
+		StartingHands:     total,
+		ExpectedPayout:    ratFloat(&acc.payout),
+		ExpectedCost:      ratFloat(cost),
+		HitFrequency:      make(map[card.HandType]float64, len(card.AllHandTypes)),
+		StdDev:            math.Sqrt(math.Max(ratFloat(variance), 0)),
+		ExactPayout:       new(big.Rat).Set(&acc.payout),
+		ExactCost:         cost,
+		ExactHitFrequency: make(map[card.HandType]*big.Rat, len(card.AllHandTypes)),
+	}
+	for _, handType := range card.AllHandTypes {
+		report.ExactHitFrequency[handType] = new(big.Rat).Set(&acc.hits[handType])
+		report.HitFrequency[handType] = ratFloat(&acc.hits[handType])
+	}
+	if cost.Sign() > 0 {
+		report.ExactRTP = new(big.Rat).Quo(&acc.payout, cost)
+		report.ExactHouseEdge = new(big.Rat).Sub(big.NewRat(1, 1), report.ExactRTP)
+		report.RTP = ratFloat(report.ExactRTP)
+		report.HouseEdge = ratFloat(report.ExactHouseEdge)
+	}
+	return report
+}
+
+func ratFloat(r *big.Rat) float64 {
+	f, _ := r.Float64()
+	return f
+}
+
+// 依策略玩完一個狀態, spent 為目前為止的換牌花費, weight 為這個狀態的權重
+//
+// 直接結算時把整數點數乘上 weight 加進 sums; 換牌時把之後所有抽法的平均乘上 weight 加進 acc
+func (a *Analyzer) play(run *rtpRun, state State, spent, weight int64, sums *rtpIntSums, acc *rtpAccumulator) error {
+	decision, err := run.strategy.Decide(state)
+	if err != nil {
+		return err
+	}
+	config := run.config
+	canDiscard := config.MaxRounds <= 0 || state.DiscardCount < config.MaxRounds
+	if decision.Settle || len(decision.Discard) == 0 || !canDiscard {
+		handType := a.Rules.GetHandType(state.Hand)
+		sums.add(handType, int64(a.Paytable.Payout(handType)), spent, int64(config.GameCost), weight)
+		return nil
+	}
+
+	kept, err := keptCards(state.Hand, decision.Discard)
+	if err != nil {
+		return err
+	}
+	remaining, err := a.remainingDeck(state.Hand, state.Dead)
+	if err != nil {
+		return err
+	}
+	draw := len(decision.Discard)
+	if draw > len(remaining) {
+		return i18n.Errorf("error.not_enough_cards", len(remaining), draw)
+	}
+	dead := append([]*card.Card{}, state.Dead...)
+	for _, idx := range decision.Discard {
+		dead = append(dead, state.Hand[idx])
+	}
+	spent += int64(config.DiscardCost().At(state.DiscardCount))
+	combinations := utility.NewCombinations(remaining, draw)
+
+	// 每種抽法機率相同, 先加總再除以抽法數
+	draws := &rtpAccumulator{}
+	var drawSums rtpIntSums
+	combinations.Each(func(drawn []*card.Card) bool {
+		hand := append(append(make([]*card.Card, 0, len(state.Hand)), kept...), drawn...)
+		err = a.play(run, State{Hand: hand, Dead: dead, DiscardCount: state.DiscardCount + 1}, spent, 1, &drawSums, draws)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	draws.addInts(&drawSums, big.NewRat(1, 1))
+	draws.scale(big.NewRat(weight, combinations.Count()))
+	acc.merge(draws)
+	return nil
+}
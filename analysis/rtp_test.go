@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"math"
+	"math-discard-card/card"
+	"math/big"
+	"testing"
+)
+
+func TestRTP(t *testing.T) {
+	// 2~4 梅花、紅心、黑桃共9張, 兩對1、葫蘆10
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	analyzer := NewAnalyzer(rules, paytable)
+
+	tests := []struct {
+		name      string
+		maxRounds int
+		payout    float64
+		cost      float64
+		stdDev    float64
+		fullHouse float64
+		exactRTP  *big.Rat
+		exactFull *big.Rat
+	}{
+		{"OneRound", 1, 7.10714285714285, 2.857142857142856, 4.314634564893227, 0.6785714285714285, big.NewRat(199, 80), big.NewRat(19, 28)},
+		{"TwoRounds", 2, 10, 3.5, 1.085620296683623, 1, big.NewRat(20, 7), big.NewRat(1, 1)},
+	}
+
+	for _, tt := range tests {
+		config := GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: tt.maxRounds}
+		strategy, err := analyzer.NewStrategy("optimal", config)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		report, err := analyzer.RTP(config, strategy)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if report.StartingHands != 126 {
+			t.Errorf("%s: expected 126 starting hands, got %d", tt.name, report.StartingHands)
+		}
+		checks := []struct {
+			field    string
+			got      float64
+			expected float64
+		}{
+			{"payout", report.ExpectedPayout, tt.payout},
+			{"cost", report.ExpectedCost, tt.cost},
+			{"RTP", report.RTP, tt.payout / tt.cost},
+			{"house edge", report.HouseEdge, 1 - tt.payout/tt.cost},
+			{"std dev", report.StdDev, tt.stdDev},
+			{"full house", report.HitFrequency[card.FullHouse], tt.fullHouse},
+			{"two pair", report.HitFrequency[card.TwoPair], 1 - tt.fullHouse},
+		}
+		for _, check := range checks {
+			if math.Abs(check.got-check.expected) > 1e-9 {
+				t.Errorf("%s: expected %s %f, got %f", tt.name, check.field, check.expected, check.got)
+			}
+		}
+		if report.ExactRTP.Cmp(tt.exactRTP) != 0 || report.ExactHitFrequency[card.FullHouse].Cmp(tt.exactFull) != 0 {
+			t.Errorf("%s: expected exact RTP %s and full house %s, got %s and %s", tt.name,
+				tt.exactRTP.RatString(), tt.exactFull.RatString(), report.ExactRTP.RatString(), report.ExactHitFrequency[card.FullHouse].RatString())
+		}
+		if edge := new(big.Rat).Sub(big.NewRat(1, 1), tt.exactRTP); report.ExactHouseEdge.Cmp(edge) != 0 {
+			t.Errorf("%s: expected exact house edge %s, got %s", tt.name, edge.RatString(), report.ExactHouseEdge.RatString())
+		}
+		if payout := new(big.Rat).Mul(report.ExactRTP, report.ExactCost); report.ExactPayout.Cmp(payout) != 0 {
+			t.Errorf("%s: expected exact payout %s, got %s", tt.name, payout.RatString(), report.ExactPayout.RatString())
+		}
+		sum := new(big.Rat)
+		for _, hit := range report.ExactHitFrequency {
+			sum.Add(sum, hit)
+		}
+		if sum.Cmp(big.NewRat(1, 1)) != 0 {
+			t.Errorf("%s: expected exact hit frequencies to sum to 1, got %s", tt.name, sum.RatString())
+		}
+	}
+}
+
+func TestRTPNeverDiscard(t *testing.T) {
+	analyzer := NewAnalyzer(nil, nil)
+	config := GameConfig{GameCost: 1}
+	report, err := analyzer.RTP(config, NeverDiscard{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.StartingHands != 2598960 {
+		t.Errorf("expected 2598960 starting hands, got %d", report.StartingHands)
+	}
+	expected := map[card.HandType]int64{
+		card.HighCard: 1302540, card.Pair: 1098240, card.TwoPair: 123552, card.ThreeOfAKind: 54912,
+		card.Straight: 10200, card.Flush: 5108, card.FullHouse: 3744, card.FourOfAKind: 624,
+		card.StraightFlush: 36, card.RoyalFlush: 4,
+	}
+	var payout int64
+	for handType, count := range expected {
+		if got := report.HitFrequency[handType]; math.Abs(got-float64(count)/2598960) > 1e-12 {
+			t.Errorf("expected %s frequency %d/2598960, got %f", handType.ToString(), count, got)
+		}
+		payout += count * int64(handType.GetOdds())
+	}
+	if math.Abs(report.RTP-float64(payout)/2598960) > 1e-9 {
+		t.Errorf("expected RTP %f, got %f", float64(payout)/2598960, report.RTP)
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	analyzer := NewAnalyzer(nil, nil)
+	for _, name := range StrategyNames {
+		strategy, err := analyzer.NewStrategy(name, GameConfig{})
+		if err != nil || strategy.Name() != name {
+			t.Errorf("expected strategy %s, got %v, %v", name, strategy, err)
+		}
+	}
+	if _, err := analyzer.NewStrategy("unknown", GameConfig{}); err == nil {
+		t.Errorf("expected error for unknown strategy")
+	}
+}
+
+func TestRTPPayTo(t *testing.T) {
+	// x to 1 退還的押注是開局花費: 開局花費3時, 兩對 0 to 1、葫蘆 7 to 1 與兩對 3 for 1、葫蘆 10 for 1 相同
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	payTo := &card.Paytable{Version: "to", Mode: card.PayTo, Pays: map[card.HandType]int{card.TwoPair: 0, card.FullHouse: 7}}
+	payFor := &card.Paytable{Version: "for", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 3, card.FullHouse: 10}}
+	config := GameConfig{GameCost: 3, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: 1}
+
+	var reports []*RTPReport
+	for _, paytable := range []*card.Paytable{payTo, payFor} {
+		analyzer := NewAnalyzer(rules, paytable)
+		strategy, err := analyzer.NewStrategy("optimal", config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		report, err := analyzer.RTP(config, strategy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if reports[0].ExactRTP.Cmp(reports[1].ExactRTP) != 0 {
+		t.Errorf("expected PayTo to return the stake, got RTP %s vs %s", reports[0].ExactRTP.RatString(), reports[1].ExactRTP.RatString())
+	}
+	if payTo.Stake != 0 {
+		t.Errorf("expected RTP not to modify the paytable")
+	}
+}
+
+func TestRTPHandSize(t *testing.T) {
+	// 2~4 梅花、紅心、黑桃共9張, 6張手牌取最大的5張: 三種點數各2張時為兩對, 其他都能組成葫蘆
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	analyzer := NewAnalyzer(rules, paytable)
+
+	report, err := analyzer.RTP(GameConfig{GameCost: 1, HandSize: 6}, NeverDiscard{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.StartingHands != 84 {
+		t.Errorf("expected 84 starting hands, got %d", report.StartingHands)
+	}
+	if math.Abs(report.HitFrequency[card.FullHouse]-57.0/84) > 1e-12 || math.Abs(report.HitFrequency[card.TwoPair]-27.0/84) > 1e-12 {
+		t.Errorf("expected full house 57/84 and two pair 27/84, got %v", report.HitFrequency)
+	}
+
+	for _, handSize := range []int{4, 10} {
+		if _, err := analyzer.RTP(GameConfig{GameCost: 1, HandSize: handSize}, NeverDiscard{}); err == nil {
+			t.Errorf("expected hand size %d to be rejected", handSize)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"math-discard-card/card"
+	"math/big"
+	"sort"
+)
+
+// HoldResult 某種留牌/換牌選擇的期望值
+type HoldResult struct {
+	Discard    []int     `json:"discard"`      // 要換掉的手牌位置, 由小到大
+	Cost       int       `json:"cost"`         // 換牌花費, 不換牌時為0
+	Outcomes   *Outcomes `json:"outcomes"`     // 換牌後的牌型分布
+	EV         float64   `json:"ev"`           // 期望拿回的點數
+	NetEV      float64   `json:"net_ev"`       // 扣掉換牌花費後的期望點數
+	ExactEV    *big.Rat  `json:"exact_ev"`     // 期望拿回點數的精確分數
+	ExactNetEV *big.Rat  `json:"exact_net_ev"` // 淨期望點數的精確分數
+	Best       bool      `json:"best"`         // 是否為最佳選擇
+}
+
+// ExpectedPayout 依賠率表計算押注1單位的期望拿回點數
+func (o *Outcomes) ExpectedPayout(paytable *card.Paytable) float64 {
+	if o.Total == 0 {
+		return 0
+	}
+	var sum int64
+	for handType, count := range o.Counts {
+		sum += count * int64(paytable.Payout(handType))
+	}
+	return float64(sum) / float64(o.Total)
+}
+
+// ExactExpectedPayout 同 ExpectedPayout, 回傳精確分數
+func (o *Outcomes) ExactExpectedPayout(paytable *card.Paytable) *big.Rat {
+	if o.Total == 0 {
+		return new(big.Rat)
+	}
+	var sum int64
+	for handType, count := range o.Counts {
+		sum += count * int64(paytable.Payout(handType))
+	}
+	return big.NewRat(sum, o.Total)
+}
+
+// SolveHold 以標準規則與預設賠率表列出所有換牌選擇, 見 Analyzer.SolveHold
+func SolveHold(hand []*card.Card, discardCost int, deadCards []*card.Card) ([]HoldResult, error) {
+	return NewAnalyzer(nil, nil).SolveHold(hand, discardCost, deadCards)
+}
+
+// SolveHold 計算手牌所有留牌/換牌組合的期望值, 依淨期望值由大到小排序, 第一筆標示為最佳選擇
+//
+// discardCost 為這次換牌的花費(CardGame.curDiscardCost), 只有真的換牌時才扣除;
+// 以精確分數比較淨期望值, 相同時換比較少張的排前面
+func (a *Analyzer) SolveHold(hand []*card.Card, discardCost int, deadCards []*card.Card) ([]HoldResult, error) {
+	results := make([]HoldResult, 0, 1<<len(hand))
+	for mask := 0; mask < 1<<len(hand); mask++ {
+		discard := []int{}
+		for i := range hand {
+			if mask&(1<<i) != 0 {
+				discard = append(discard, i)
+			}
+		}
+		outcomes, err := a.DiscardOutcomes(hand, discard, deadCards)
+		if err != nil {
+			return nil, err
+		}
+		result := HoldResult{
+			Discard:  discard,
+			Outcomes: outcomes,
+			EV:       outcomes.ExpectedPayout(a.Paytable),
+			ExactEV:  outcomes.ExactExpectedPayout(a.Paytable),
+		}
+		if len(discard) > 0 {
+			result.Cost = discardCost
+		}
+		result.NetEV = result.EV - float64(result.Cost)
+		result.ExactNetEV = new(big.Rat).Sub(result.ExactEV, big.NewRat(int64(result.Cost), 1))
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if cmp := results[i].ExactNetEV.Cmp(results[j].ExactNetEV); cmp != 0 {
+			return cmp > 0
+		}
+		return len(results[i].Discard) < len(results[j].Discard)
+	})
+	if len(results) > 0 {
+		results[0].Best = true
+	}
+	return results, nil
+}
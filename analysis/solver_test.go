@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"math"
+	"math-discard-card/card"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestSolveHold(t *testing.T) {
+	tests := []struct {
+		name     string
+		hand     string
+		cost     int
+		discard  []int
+		expected float64
+		exact    *big.Rat
+	}{
+		{"KeepRoyal", "Ah Kh Qh Jh Th", 1, []int{}, 1000, big.NewRat(1000, 1)},
+		{"DrawToRoyal", "Ah Kh Qh Jh 2c", 1, []int{4}, float64(1000+8*30+3*20+12*2)/47 - 1, big.NewRat(1000+8*30+3*20+12*2-47, 47)},
+		// 換牌太貴時不換
+		{"TooExpensive", "Ah Kh Qh Jh 2c", 100, []int{}, 0, new(big.Rat)},
+	}
+
+	for _, tt := range tests {
+		results, err := SolveHold(card.MustParseHand(tt.hand), tt.cost, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(results) != 32 {
+			t.Fatalf("%s: expected 32 results, got %d", tt.name, len(results))
+		}
+		best := results[0]
+		if !best.Best || !reflect.DeepEqual(best.Discard, tt.discard) {
+			t.Errorf("%s: expected best discard %v, got %v (best=%v)", tt.name, tt.discard, best.Discard, best.Best)
+		}
+		if math.Abs(best.NetEV-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected net EV %f, got %f", tt.name, tt.expected, best.NetEV)
+		}
+		if best.ExactNetEV.Cmp(tt.exact) != 0 {
+			t.Errorf("%s: expected exact net EV %v, got %v", tt.name, tt.exact, best.ExactNetEV)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Best {
+				t.Errorf("%s: result %d should not be marked best", tt.name, i)
+			}
+			if results[i].NetEV > results[i-1].NetEV {
+				t.Errorf("%s: results are not sorted at %d", tt.name, i)
+			}
+		}
+	}
+}
+
+func TestExpectedPayoutPayTo(t *testing.T) {
+	outcomes := &Outcomes{Total: 4, Counts: map[card.HandType]int64{card.HighCard: 2, card.Pair: 2}}
+	paytable := (&card.Paytable{Version: "test", Mode: card.PayTo, Pays: map[card.HandType]int{card.Pair: 1}}).WithStake(1)
+	if ev := outcomes.ExpectedPayout(paytable); ev != 1 {
+		t.Errorf("expected EV 1, got %f", ev)
+	}
+	if ev := outcomes.ExactExpectedPayout(paytable); ev.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected exact EV 1, got %v", ev)
+	}
+}
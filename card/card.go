@@ -2,6 +2,7 @@ package card
 
 import (
 	"fmt"
+	"math-discard-card/i18n"
 	"sort"
 	"strings"
 
@@ -15,6 +16,7 @@ const (
 	Diamonds
 	Hearts
 	Spades
+	Joker // 鬼牌, Number 為鬼牌的編號(從1開始)
 )
 
 func (s SuitType) ToString() string {
@@ -27,15 +29,26 @@ func (s SuitType) ToString() string {
 		return "♦"
 	case Clubs:
 		return "♣"
+	case Joker:
+		return "🃏"
 	default:
-		return "尚未定義"
+		return i18n.T(i18n.DefaultLocale, "undefined")
 	}
 }
 
+// Name 依語系取得花色名稱, 例如 "黑桃"、"Spades"
+func (s SuitType) Name(locale i18n.Locale) string {
+	if s < Clubs || s > Joker {
+		return i18n.T(locale, "undefined")
+	}
+	return i18n.T(locale, "suit."+suitNames[s])
+}
+
 type Card struct {
-	Idx    int
+	Idx    int // 牌面編號(花色*13+點數), 多副牌時相同牌面的 Idx 相同
 	Suit   SuitType
 	Number int
+	Copy   int // 多副牌時是第幾副(從0開始)
 }
 
 func NewCard(suit SuitType, number int) *Card {
@@ -46,52 +59,75 @@ func NewCard(suit SuitType, number int) *Card {
 	}
 }
 
+// NewJoker 建立第 n 張鬼牌(n從1開始), Idx 接在52張牌之後
+func NewJoker(n int) *Card {
+	return NewCard(Joker, n)
+}
+
+// IsJoker 是否為鬼牌
+func (c *Card) IsJoker() bool {
+	return c.Suit == Joker
+}
+
 func (c *Card) ToString() string {
 	return fmt.Sprintf("%s%d", c.Suit.ToString(), c.Number)
 }
 
 type HandType int
 
+// 牌型由小到大排列, 數字越大牌型越大
+//
+// 加入兩對、五條、同花大順時插在原本的牌型之間, 三條以上的數字都改變了;
+// 舊版存下的數字要以 LegacyHandType 轉換, 需要保存時請用 MarshalText 的名稱
 const (
 	HighCard HandType = iota
 	Pair
+	TwoPair
 	ThreeOfAKind
 	Straight
 	Flush
 	FullHouse
 	FourOfAKind
 	StraightFlush
+	FiveOfAKind // 五條, 只有百搭或多副牌時才會出現
+	RoyalFlush  // 同花大順(10JQKA)
 )
 
+// AllHandTypes 所有牌型, 由小到大
+var AllHandTypes = []HandType{HighCard, Pair, TwoPair, ThreeOfAKind, Straight, Flush, FullHouse, FourOfAKind, StraightFlush, FiveOfAKind, RoyalFlush}
+
+// 舊版牌型的數字依序對應的牌型: HighCard=0、Pair=1、ThreeOfAKind=2、Straight=3、Flush=4、FullHouse=5、FourOfAKind=6、StraightFlush=7
+var legacyHandTypes = []HandType{HighCard, Pair, ThreeOfAKind, Straight, Flush, FullHouse, FourOfAKind, StraightFlush}
+
+// LegacyHandType 將舊版(還沒有兩對、五條、同花大順時)的牌型數字轉換為目前的牌型
+func LegacyHandType(n int) (HandType, error) {
+	if n < 0 || n >= len(legacyHandTypes) {
+		return HighCard, i18n.Errorf("error.legacy_hand_type", n)
+	}
+	return legacyHandTypes[n], nil
+}
+
 func (h HandType) ToString() string {
-	switch h {
-	case HighCard:
-		return "高牌"
-	case Pair:
-		return "對子"
-	case ThreeOfAKind:
-		return "三條"
-	case Straight:
-		return "順子"
-	case Flush:
-		return "同花"
-	case FullHouse:
-		return "葫蘆"
-	case FourOfAKind:
-		return "四條"
-	case StraightFlush:
-		return "同花順"
-	default:
-		return "尚未定義"
+	return h.Name(i18n.DefaultLocale)
+}
+
+// Name 依語系取得牌型名稱
+func (h HandType) Name(locale i18n.Locale) string {
+	if h < HighCard || h > RoyalFlush {
+		return i18n.T(locale, "undefined")
 	}
+	return i18n.T(locale, "hand."+handTypeNames[h])
 }
 
+// GetOdds 預設賠率, 依牌型由小到大不遞減, 百搭組成的同花大順不會比五條賠得少
 func (h HandType) GetOdds() int {
 	switch h {
 	case HighCard:
 		return 0
 	case Pair:
 		return 2
+	case TwoPair:
+		return 2
 	case ThreeOfAKind:
 		return 10
 	case Straight:
@@ -104,6 +140,10 @@ func (h HandType) GetOdds() int {
 		return 250
 	case StraightFlush:
 		return 1000
+	case FiveOfAKind:
+		return 1000
+	case RoyalFlush:
+		return 1000
 	default:
 		log.Errorf("尚未定義的HandType牌型賠率: %d", h)
 		return 0
@@ -121,28 +161,26 @@ func ShowCards(cards []*Card) {
 	log.Println(str.String())
 }
 
+// GetHandType 取得最大的牌型, 超過5張時會取最大的5張組合
+// 5~7張標準牌會走查表不配置記憶體, 其餘情況才用 Evaluate 計算
 func GetHandType(cards []*Card) HandType {
-	if IsStraightFlush(cards) {
-		return StraightFlush
-	} else if IsFourOfAKind(cards) {
-		return FourOfAKind
-	} else if IsFullHouse(cards) {
-		return FullHouse
-	} else if IsThreeOfAKind(cards) {
-		return ThreeOfAKind
-	} else if IsStraight(cards) {
-		return Straight
-	} else if IsFlush(cards) {
-		return Flush
-	} else if IsPair(cards) {
-		return Pair
-	} else {
-		return HighCard
+	if class, ok := handClass(cards); ok {
+		return classHandType(class)
 	}
+	return Evaluate(cards).Type
 }
 
+// IsHandType 手牌中是否有這個牌型, 只看自然牌: 鬼牌會被略過, 百搭點數當成一般點數;
+// 同樣的牌在有鬼牌或百搭的規則下可能與 Rules.Evaluate 的結果不同, 要考慮百搭請用 Rules.Evaluate
+//
+// 個別的 IsXxx 也只看自然牌, 但不會略過鬼牌, 傳入的牌不應包含鬼牌
 func IsHandType(cards []*Card, handType HandType) bool {
+	cards = naturalCards(cards)
 	switch handType {
+	case RoyalFlush:
+		return IsRoyalFlush(cards)
+	case FiveOfAKind:
+		return IsFiveOfAKind(cards)
 	case StraightFlush:
 		return IsStraightFlush(cards)
 	case FourOfAKind:
@@ -155,6 +193,8 @@ func IsHandType(cards []*Card, handType HandType) bool {
 		return IsStraight(cards)
 	case Flush:
 		return IsFlush(cards)
+	case TwoPair:
+		return IsTwoPair(cards)
 	case Pair:
 		return IsPair(cards)
 	case HighCard:
@@ -169,6 +209,38 @@ func IsOnlyHighCard(cards []*Card) bool {
 	return !IsStraightFlush(cards) && !IsFourOfAKind(cards) && !IsFullHouse(cards) && !IsThreeOfAKind(cards) && !IsStraight(cards) && !IsFlush(cards) && !IsPair(cards)
 }
 
+// 去掉鬼牌後的手牌, 沒有鬼牌時直接回傳原本的 slice
+func naturalCards(cards []*Card) []*Card {
+	for i, card := range cards {
+		if !card.IsJoker() {
+			continue
+		}
+		natural := append([]*Card{}, cards[:i]...)
+		for _, card := range cards[i+1:] {
+			if !card.IsJoker() {
+				natural = append(natural, card)
+			}
+		}
+		return natural
+	}
+	return cards
+}
+
+// IsFiveOfAKind 是否有5張同點數的自然牌, 只有多副牌時才會出現; 鬼牌不算百搭
+func IsFiveOfAKind(cards []*Card) bool {
+	numberDic := make(map[int]int)
+	for _, card := range cards {
+		if card.IsJoker() {
+			continue
+		}
+		numberDic[card.Number]++
+		if numberDic[card.Number] >= 5 {
+			return true
+		}
+	}
+	return false
+}
+
 func IsFlush(cards []*Card) bool {
 	suitDics := make(map[SuitType]int)
 	for _, card := range cards {
@@ -235,6 +307,35 @@ func GetPairIndices(cards []*Card) []int {
 	return nil
 }
 
+// IsTwoPair 是否有兩個不同點數的對子(三條或四條也算)
+func IsTwoPair(cards []*Card) bool {
+	numberDic := make(map[int]int)
+	pairCount := 0
+	for _, card := range cards {
+		numberDic[card.Number]++
+		if numberDic[card.Number] == 2 {
+			pairCount++
+		}
+	}
+	return pairCount >= 2
+}
+
+// GetTwoPairIndices 兩個對子在手牌中的索引
+func GetTwoPairIndices(cards []*Card) []int {
+	numberIndices := make(map[int][]int)
+	var indices []int
+	for i, card := range cards {
+		numberIndices[card.Number] = append(numberIndices[card.Number], i)
+		if len(numberIndices[card.Number]) == 2 {
+			indices = append(indices, numberIndices[card.Number]...)
+			if len(indices) == 4 {
+				return indices
+			}
+		}
+	}
+	return nil
+}
+
 func IsFullHouse(cards []*Card) bool {
 	numberDic := make(map[int]int)
 	for _, card := range cards {
@@ -272,7 +373,7 @@ func GetFullHouseIndices(cards []*Card) []int {
 	}
 
 	if threeOfAKindIndices != nil && pairIndices != nil {
-		return append(threeOfAKindIndices, pairIndices...)
+		return append(append([]int{}, threeOfAKindIndices...), pairIndices...)
 	}
 
 	for _, indices := range numberIndices {
@@ -280,7 +381,7 @@ func GetFullHouseIndices(cards []*Card) []int {
 			if threeOfAKindIndices == nil {
 				threeOfAKindIndices = indices[:3]
 			} else {
-				return append(threeOfAKindIndices, indices[:2]...)
+				return append(append([]int{}, threeOfAKindIndices...), indices[:2]...)
 			}
 		}
 	}
@@ -310,6 +411,30 @@ func GetFourOfAKindIndices(cards []*Card) []int {
 	return nil
 }
 
+// IsRoyalFlush 是否有同花的 10JQKA
+func IsRoyalFlush(cards []*Card) bool {
+	return GetRoyalFlushIndices(cards) != nil
+}
+
+// GetRoyalFlushIndices 同花大順在手牌中的索引
+func GetRoyalFlushIndices(cards []*Card) []int {
+	for suit := Clubs; suit <= Spades; suit++ {
+		var indices []int
+		for _, number := range []int{10, 11, 12, 13, 1} {
+			for i, card := range cards {
+				if card.Suit == suit && card.Number == number {
+					indices = append(indices, i)
+					break
+				}
+			}
+		}
+		if len(indices) == 5 {
+			return indices
+		}
+	}
+	return nil
+}
+
 func IsStraightFlush(cards []*Card) bool {
 	suitCards := make(map[SuitType][]*Card)
 	for _, card := range cards {
@@ -356,48 +481,54 @@ func IsStraight(cards []*Card) bool {
 	return false
 }
 
+// GetStraightFlushIndices 最大的同花順在手牌中的索引, 由小到大排列(A2345 的 A 在最前面)
 func GetStraightFlushIndices(cards []*Card) []int {
-	suitIndices := make(map[SuitType][]*Card)
-	for _, card := range cards {
-		suitIndices[card.Suit] = append(suitIndices[card.Suit], card)
-	}
-
-	for _, suitCards := range suitIndices {
-		if len(suitCards) < 5 {
-			continue
+	var best []int
+	bestHigh := 0
+	for suit := Clubs; suit <= Spades; suit++ {
+		positions := make(map[int]int)
+		for i, card := range cards {
+			if _, ok := positions[card.Number]; !ok && card.Suit == suit {
+				positions[card.Number] = i
+			}
 		}
-		sort.Slice(suitCards, func(i, j int) bool {
-			return suitCards[i].Number < suitCards[j].Number
-		})
-		straightIndices := GetStraightIndices(suitCards)
-		if len(straightIndices) > 0 {
-			return straightIndices
+		if indices, high := straightIndices(positions); indices != nil && high > bestHigh {
+			best, bestHigh = indices, high
 		}
 	}
-	return nil
+	return best
 }
 
+// GetStraightIndices 最大的順子在手牌中的索引, 由小到大排列(A2345 的 A 在最前面)
 func GetStraightIndices(cards []*Card) []int {
-	values := make(map[int]int)
+	positions := make(map[int]int)
 	for i, card := range cards {
-		values[card.Number] = i
-	}
-
-	var nums []int
-	for num := range values {
-		nums = append(nums, num)
-	}
-	sort.Ints(nums)
-
-	for i := 0; i <= len(nums)-5; i++ {
-		if nums[i+4]-nums[i] == 4 {
-			return []int{values[nums[i]], values[nums[i+1]], values[nums[i+2]], values[nums[i+3]], values[nums[i+4]]}
+		if _, ok := positions[card.Number]; !ok {
+			positions[card.Number] = i
 		}
 	}
+	indices, _ := straightIndices(positions)
+	return indices
+}
 
-	if values[1] != 0 && values[10] != 0 && values[11] != 0 && values[12] != 0 && values[13] != 0 {
-		return []int{values[1], values[10], values[11], values[12], values[13]}
+// 從點數對應的位置中找出最大的順子, 回傳索引與最大的點數
+func straightIndices(positions map[int]int) ([]int, int) {
+	for high := aceHigh; high >= 5; high-- {
+		indices := make([]int, 0, 5)
+		for rank := high - 4; rank <= high; rank++ {
+			number := rank
+			if rank == aceHigh {
+				number = 1
+			}
+			idx, ok := positions[number]
+			if !ok {
+				break
+			}
+			indices = append(indices, idx)
+		}
+		if len(indices) == 5 {
+			return indices, high
+		}
 	}
-
-	return nil
+	return nil, 0
 }
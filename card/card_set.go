@@ -0,0 +1,129 @@
+package card
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// CardSet 以 Card.Idx 作為 bit 位置的牌組集合, 可以直接用位元運算處理死牌、剩餘牌堆等計算
+type CardSet uint64
+
+// FullDeckSet 標準52張牌
+const FullDeckSet CardSet = ((1 << 52) - 1) << 1
+
+// NewCardSet 由傳入的牌建立集合
+func NewCardSet(cards ...*Card) CardSet {
+	var s CardSet
+	for _, card := range cards {
+		s.Add(card)
+	}
+	return s
+}
+
+// CardFromIdx 由 Idx 建立對應的牌
+func CardFromIdx(idx int) *Card {
+	return NewCard(SuitType((idx-1)/13), (idx-1)%13+1)
+}
+
+// RankMask 某個點數的所有花色
+func RankMask(number int) CardSet {
+	var s CardSet
+	for suit := Clubs; suit <= Spades; suit++ {
+		s |= 1 << uint(int(suit)*13+number)
+	}
+	return s
+}
+
+// SuitMask 某個花色的所有點數
+func SuitMask(suit SuitType) CardSet {
+	return CardSet(0x1FFF) << uint(int(suit)*13+1)
+}
+
+// Add 加入一張牌
+func (s *CardSet) Add(card *Card) {
+	*s |= 1 << uint(card.Idx)
+}
+
+// Remove 移除一張牌
+func (s *CardSet) Remove(card *Card) {
+	*s &^= 1 << uint(card.Idx)
+}
+
+// Contains 是否包含某張牌
+func (s CardSet) Contains(card *Card) bool {
+	return s&(1<<uint(card.Idx)) != 0
+}
+
+// Union 聯集
+func (s CardSet) Union(other CardSet) CardSet {
+	return s | other
+}
+
+// Intersect 交集
+func (s CardSet) Intersect(other CardSet) CardSet {
+	return s & other
+}
+
+// Difference 移除 other 中的牌
+func (s CardSet) Difference(other CardSet) CardSet {
+	return s &^ other
+}
+
+// Count 牌數
+func (s CardSet) Count() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// Each 依 Idx 由小到大走訪每張牌, fn 回傳 false 時停止
+func (s CardSet) Each(fn func(card *Card) bool) {
+	for s != 0 {
+		idx := bits.TrailingZeros64(uint64(s))
+		s &= s - 1
+		if !fn(CardFromIdx(idx)) {
+			return
+		}
+	}
+}
+
+// Cards 轉成依 Idx 由小到大排序的牌
+func (s CardSet) Cards() []*Card {
+	cards := make([]*Card, 0, s.Count())
+	s.Each(func(card *Card) bool {
+		cards = append(cards, card)
+		return true
+	})
+	return cards
+}
+
+func (s CardSet) ToString() string {
+	var str strings.Builder
+	s.Each(func(card *Card) bool {
+		if str.Len() != 0 {
+			str.WriteString(",")
+		}
+		str.WriteString(card.ToString())
+		return true
+	})
+	return str.String()
+}
+
+// HandType 取得集合中最大的牌型, 5~7張標準牌時走查表不配置記憶體
+func (s CardSet) HandType() HandType {
+	n := s.Count()
+	if n >= 5 && n <= 7 && s&^FullDeckSet == 0 {
+		var encoded [7]uint32
+		i := 0
+		for rest := s; rest != 0; rest &= rest - 1 {
+			idx := bits.TrailingZeros64(uint64(rest))
+			encoded[i] = encodeCard(SuitType((idx-1)/13), (idx-1)%13+1)
+			i++
+		}
+		return classHandType(bestClass(encoded[:n]))
+	}
+	return s.Evaluate().Type
+}
+
+// Evaluate 取得集合中最大的5張組合的完整評比結果
+func (s CardSet) Evaluate() HandRank {
+	return Evaluate(s.Cards())
+}
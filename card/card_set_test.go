@@ -0,0 +1,100 @@
+package card
+
+import (
+	"testing"
+)
+
+func TestCardSetBasicOperations(t *testing.T) {
+	aceClubs := NewCard(Clubs, 1)
+	kingSpades := NewCard(Spades, 13)
+
+	var set CardSet
+	set.Add(aceClubs)
+	set.Add(kingSpades)
+	if !set.Contains(aceClubs) || !set.Contains(kingSpades) {
+		t.Errorf("Expected set to contain both cards")
+	}
+	if set.Count() != 2 {
+		t.Errorf("Expected count 2, got %d", set.Count())
+	}
+
+	set.Remove(aceClubs)
+	if set.Contains(aceClubs) {
+		t.Errorf("Expected set to not contain removed card")
+	}
+
+	other := NewCardSet(aceClubs, kingSpades)
+	if set.Union(other) != other {
+		t.Errorf("Expected union to equal %v", other.ToString())
+	}
+	if other.Intersect(set) != set {
+		t.Errorf("Expected intersect to equal %v", set.ToString())
+	}
+	if other.Difference(set) != NewCardSet(aceClubs) {
+		t.Errorf("Expected difference to only contain %v", aceClubs.ToString())
+	}
+}
+
+func TestCardSetConversion(t *testing.T) {
+	if FullDeckSet.Count() != 52 {
+		t.Fatalf("Expected 52 cards, got %d", FullDeckSet.Count())
+	}
+	cards := FullDeckSet.Cards()
+	for i, card := range cards {
+		if card.Idx != i+1 {
+			t.Errorf("Expected idx %d, got %d", i+1, card.Idx)
+		}
+		if *CardFromIdx(card.Idx) != *card {
+			t.Errorf("CardFromIdx(%d) mismatch", card.Idx)
+		}
+	}
+	if NewCardSet(cards...) != FullDeckSet {
+		t.Errorf("Expected round trip to return the full deck")
+	}
+}
+
+func TestCardSetMasks(t *testing.T) {
+	for number := 1; number <= 13; number++ {
+		mask := RankMask(number)
+		if mask.Count() != 4 {
+			t.Errorf("RankMask(%d): expected 4 cards, got %d", number, mask.Count())
+		}
+		mask.Each(func(card *Card) bool {
+			if card.Number != number {
+				t.Errorf("RankMask(%d) contains %v", number, card.ToString())
+			}
+			return true
+		})
+	}
+	var all CardSet
+	for suit := Clubs; suit <= Spades; suit++ {
+		mask := SuitMask(suit)
+		if mask.Count() != 13 {
+			t.Errorf("SuitMask(%d): expected 13 cards, got %d", suit, mask.Count())
+		}
+		all = all.Union(mask)
+	}
+	if all != FullDeckSet {
+		t.Errorf("Expected suit masks to cover the full deck")
+	}
+}
+
+func TestCardSetHandType(t *testing.T) {
+	fours := RankMask(9)
+	fours.Add(NewCard(Hearts, 2))
+	if fours.HandType() != FourOfAKind {
+		t.Errorf("Expected four of a kind, got %v", fours.HandType().ToString())
+	}
+
+	flush := SuitMask(Diamonds).Intersect(NewCardSet(testHand([2]int{1, 2}, [2]int{1, 5}, [2]int{1, 9}, [2]int{1, 11}, [2]int{1, 13}, [2]int{0, 13})...))
+	if flush.HandType() != Flush || flush.Evaluate().Type != Flush {
+		t.Errorf("Expected flush, got %v", flush.HandType().ToString())
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		fours.HandType()
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocs, got %v", allocs)
+	}
+}
@@ -0,0 +1,156 @@
+package card
+
+import (
+	"math-discard-card/i18n"
+	"sort"
+)
+
+// DeckSpec 描述一副牌(或牌靴)包含哪些點數、花色以及幾副
+//
+// 多副牌時相同牌面的牌 Idx 相同, 以 Card.Copy 區分是第幾副
+// 順子為牌組中點數順序上連續的5張, A 可以接在最小的4個點數前面, 例如短牌(6~A)的 A6789
+type DeckSpec struct {
+	Numbers []int      // 包含的點數(1=A ~ 13=K), nil 表示 A~K 全部
+	Suits   []SuitType // 包含的花色, nil 表示四種花色
+	Copies  int        // 幾副牌, 0 視為1副
+}
+
+var (
+	// StandardDeck 標準52張牌
+	StandardDeck = DeckSpec{}
+	// ShortDeck 36張短牌(6~A)
+	ShortDeck = DeckSpec{Numbers: []int{1, 6, 7, 8, 9, 10, 11, 12, 13}}
+	// DoubleDeck 2副標準牌組成的牌靴
+	DoubleDeck = DeckSpec{Copies: 2}
+)
+
+// 順子的點數組合, ranks 由大到小, high 為比大小用的點數
+type straightWindow struct {
+	high  int
+	ranks [5]int
+}
+
+var standardStraights = StandardDeck.straights()
+
+func (d DeckSpec) numbers() []int {
+	if d.Numbers == nil {
+		return []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
+	}
+	return d.Numbers
+}
+
+func (d DeckSpec) suits() []SuitType {
+	if d.Suits == nil {
+		return []SuitType{Clubs, Diamonds, Hearts, Spades}
+	}
+	return d.Suits
+}
+
+func (d DeckSpec) copies() int {
+	if d.Copies <= 0 {
+		return 1
+	}
+	return d.Copies
+}
+
+// Validate 檢查點數與花色是否合法且沒有重複
+func (d DeckSpec) Validate() error {
+	seenNumbers := make(map[int]bool)
+	for _, number := range d.numbers() {
+		if number < 1 || number > 13 || seenNumbers[number] {
+			return i18n.Errorf("error.deck_numbers", d.Numbers)
+		}
+		seenNumbers[number] = true
+	}
+	seenSuits := make(map[SuitType]bool)
+	for _, suit := range d.suits() {
+		if suit < Clubs || suit > Spades || seenSuits[suit] {
+			return i18n.Errorf("error.deck_suits", d.Suits)
+		}
+		seenSuits[suit] = true
+	}
+	if d.Copies < 0 {
+		return i18n.Errorf("error.deck_copies", d.Copies)
+	}
+	return nil
+}
+
+// Size 牌組總張數
+func (d DeckSpec) Size() int {
+	return len(d.numbers()) * len(d.suits()) * d.copies()
+}
+
+// Build 建立牌組, 依副數、Idx 由小到大排序
+func (d DeckSpec) Build() []*Card {
+	numbers := append([]int{}, d.numbers()...)
+	sort.Ints(numbers)
+	suits := append([]SuitType{}, d.suits()...)
+	sort.Slice(suits, func(i, j int) bool {
+		return suits[i] < suits[j]
+	})
+
+	deck := make([]*Card, 0, d.Size())
+	for copy := 0; copy < d.copies(); copy++ {
+		for _, suit := range suits {
+			for _, number := range numbers {
+				card := NewCard(suit, number)
+				card.Copy = copy
+				deck = append(deck, card)
+			}
+		}
+	}
+	return deck
+}
+
+// IsStandardRanks 點數是否為完整的 A~K, 是的話可以使用查表評估
+//
+// 每次評估牌型都會呼叫, 以 bit 記錄出現的點數, 不配置記憶體
+func (d DeckSpec) IsStandardRanks() bool {
+	if d.Numbers == nil {
+		return true
+	}
+	if len(d.Numbers) != 13 {
+		return false
+	}
+	var seen uint16
+	for _, number := range d.Numbers {
+		if number < 1 || number > 13 {
+			return false
+		}
+		seen |= 1 << number
+	}
+	// 13個點數都出現過就沒有重複
+	return seen == 0x3ffe
+}
+
+// 依牌組點數產生所有順子, 由大到小排序; 只有點數連續的5張才算順子, 缺少的點數不會被跳過
+func (d DeckSpec) straights() []straightWindow {
+	ranks := make([]int, 0, 13)
+	for _, number := range d.numbers() {
+		ranks = append(ranks, rankValue(number))
+	}
+	sort.Ints(ranks)
+
+	var windows []straightWindow
+	for top := len(ranks) - 1; top >= 4; top-- {
+		// 點數不重複且已排序, 頭尾相差4即為連續
+		if ranks[top]-ranks[top-4] != 4 {
+			continue
+		}
+		window := straightWindow{high: ranks[top]}
+		for i := 0; i < 5; i++ {
+			window.ranks[i] = ranks[top-i]
+		}
+		windows = append(windows, window)
+	}
+	// A 接在最小的4個點數前面, 最小的4個點數要連續
+	if len(ranks) > 5 && ranks[len(ranks)-1] == aceHigh && ranks[3]-ranks[0] == 3 {
+		window := straightWindow{high: ranks[3]}
+		for i := 0; i < 4; i++ {
+			window.ranks[i] = ranks[3-i]
+		}
+		window.ranks[4] = aceHigh
+		windows = append(windows, window)
+	}
+	return windows
+}
@@ -0,0 +1,156 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeckSpecBuild(t *testing.T) {
+	tests := []struct {
+		name string
+		spec DeckSpec
+		size int
+	}{
+		{"Standard", StandardDeck, 52},
+		{"Short", ShortDeck, 36},
+		{"Double", DoubleDeck, 104},
+		{"TwoSuitsLowRanks", DeckSpec{Numbers: []int{2, 3, 4, 5, 6, 7}, Suits: []SuitType{Hearts, Spades}}, 12},
+	}
+
+	for _, tt := range tests {
+		if err := tt.spec.Validate(); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		deck := tt.spec.Build()
+		if len(deck) != tt.size || tt.spec.Size() != tt.size {
+			t.Errorf("%s: expected %d cards, got %d", tt.name, tt.size, len(deck))
+		}
+	}
+
+	shoe := DoubleDeck.Build()
+	first, second := shoe[0], shoe[52]
+	if first.Idx != second.Idx || first.Copy != 0 || second.Copy != 1 {
+		t.Errorf("Expected duplicate faces to share Idx and differ by Copy, got %+v %+v", first, second)
+	}
+
+	for _, spec := range []DeckSpec{{Numbers: []int{0}}, {Numbers: []int{2, 2}}, {Suits: []SuitType{Joker}}, {Copies: -1}} {
+		if spec.Validate() == nil {
+			t.Errorf("Expected error for %+v", spec)
+		}
+	}
+}
+
+func TestShortDeckStraights(t *testing.T) {
+	short := &Rules{Deck: ShortDeck}
+	tests := []struct {
+		hand     string
+		expected HandType
+		ranks    []int
+	}{
+		{"As 6d 7c 8h 9c", Straight, []int{9}},
+		{"6s 7d 8c 9h Tc", Straight, []int{10}},
+		{"Ts Jd Qc Kh Ac", Straight, []int{14}},
+		{"As 6s 7s 8s 9s", StraightFlush, []int{9}},
+	}
+	for _, tt := range tests {
+		cards := MustParseHand(tt.hand)
+		rank := short.Evaluate(cards)
+		if rank.Type != tt.expected || !reflect.DeepEqual(rank.Ranks, tt.ranks) {
+			t.Errorf("%s: got %v, expected %v %v", tt.hand, rank.ToString(), tt.expected.ToString(), tt.ranks)
+		}
+		if short.GetHandType(cards) != tt.expected {
+			t.Errorf("%s: GetHandType got %v", tt.hand, short.GetHandType(cards).ToString())
+		}
+	}
+
+	wheel := short.Evaluate(MustParseHand("As 6d 7c 8h 9c"))
+	sixHigh := short.Evaluate(MustParseHand("6s 7d 8c 9h Tc"))
+	if !wheel.Less(sixHigh) {
+		t.Errorf("Expected A6789 to be the lowest short deck straight")
+	}
+	if StandardRules.Evaluate(MustParseHand("As 6d 7c 8h 9c")).Type != HighCard {
+		t.Errorf("Expected A6789 to not be a straight with a standard deck")
+	}
+}
+
+func TestGappedDeckStraights(t *testing.T) {
+	// 沒有4的牌組, 點數排序後相鄰不代表連續
+	gapped := &Rules{Deck: DeckSpec{Numbers: []int{1, 2, 3, 5, 6, 7, 8, 9, 10, 11, 12, 13}}}
+	tests := []struct {
+		hand     string
+		expected HandType
+	}{
+		{"2s 3d 5c 6h 7c", HighCard},
+		{"As 2d 3c 5h 6c", HighCard},
+		{"5s 6d 7c 8h 9c", Straight},
+		{"Ts Jd Qc Kh Ac", Straight},
+		{"2s 3s 5s 6s 7s", Flush},
+	}
+
+	for _, tt := range tests {
+		if got := gapped.Evaluate(MustParseHand(tt.hand)).Type; got != tt.expected {
+			t.Errorf("%s: got %v, expected %v", tt.hand, got.ToString(), tt.expected.ToString())
+		}
+	}
+	if windows := gapped.Deck.straights(); len(windows) != 6 {
+		t.Errorf("expected 6 straights (5~9 through T~A), got %d", len(windows))
+	}
+}
+
+func TestDoubleDeckDuplicates(t *testing.T) {
+	shoe := &Rules{Deck: DoubleDeck}
+	deck := shoe.NewDeck()
+	aceSpades := []*Card{}
+	for _, card := range deck {
+		if card.Suit == Spades && card.Number == 1 {
+			aceSpades = append(aceSpades, card)
+		}
+	}
+	if len(aceSpades) != 2 {
+		t.Fatalf("Expected 2 aces of spades, got %d", len(aceSpades))
+	}
+
+	flush := append(append([]*Card{}, aceSpades...), MustParseHand("Ks Qs Js")...)
+	if rank := shoe.Evaluate(flush); rank.Type != Flush || !reflect.DeepEqual(rank.Ranks, []int{14, 14, 13, 12, 11}) {
+		t.Errorf("Expected duplicate ace flush, got %v", rank.ToString())
+	}
+	if shoe.GetHandType(flush) != Flush {
+		t.Errorf("Expected GetHandType to handle duplicates, got %v", shoe.GetHandType(flush).ToString())
+	}
+
+	five := append(append([]*Card{}, aceSpades...), MustParseHand("Ah Ad Ac")...)
+	if shoe.GetHandType(five) != FiveOfAKind {
+		t.Errorf("Expected natural five of a kind, got %v", shoe.GetHandType(five).ToString())
+	}
+}
+
+func TestIsStandardRanks(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     DeckSpec
+		expected bool
+	}{
+		{"Nil", StandardDeck, true},
+		{"ExplicitShuffled", DeckSpec{Numbers: []int{13, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}}, true},
+		{"Short", ShortDeck, false},
+		{"Duplicate", DeckSpec{Numbers: []int{1, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}}, false},
+		{"OutOfRange", DeckSpec{Numbers: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.IsStandardRanks(); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	// 明確列出 A~K 時仍走查表, 不配置記憶體
+	rules := &Rules{Deck: DeckSpec{Numbers: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}}}
+	for _, hand := range []string{"As Ks Qs Js Ts", "2c 3d 4h 5s 7c 9d Jh"} {
+		cards := MustParseHand(hand)
+		allocs := testing.AllocsPerRun(100, func() {
+			rules.GetHandType(cards)
+		})
+		if allocs != 0 {
+			t.Errorf("%s: expected 0 allocs with explicit standard ranks, got %v", hand, allocs)
+		}
+	}
+}
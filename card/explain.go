@@ -0,0 +1,66 @@
+package card
+
+// HandResult 牌型評比結果, 加上組成牌型的牌與踢腳在原本手牌中的位置
+type HandResult struct {
+	HandRank
+	Indices []int // 組成牌型的牌在手牌中的索引
+	Kickers []int // 踢腳在手牌中的索引
+}
+
+// 各牌型由幾張牌組成, 其餘為踢腳
+var madeCardCounts = map[HandType]int{
+	HighCard:      1,
+	Pair:          2,
+	TwoPair:       4,
+	ThreeOfAKind:  3,
+	Straight:      5,
+	Flush:         5,
+	FullHouse:     5,
+	FourOfAKind:   4,
+	StraightFlush: 5,
+	FiveOfAKind:   5,
+	RoyalFlush:    5,
+}
+
+// Explain 取得牌型以及組成牌型的牌、踢腳在手牌中的位置, 鬼牌視為百搭
+func Explain(cards []*Card) HandResult {
+	return StandardRules.Explain(cards)
+}
+
+// Explain 依規則取得牌型以及組成牌型的牌、踢腳在手牌中的位置
+func (r *Rules) Explain(cards []*Card) HandResult {
+	rank := r.Evaluate(cards)
+	result := HandResult{
+		HandRank: rank,
+		Indices:  []int{},
+		Kickers:  []int{},
+	}
+
+	made := madeCardCounts[rank.Type]
+	used := make([]bool, len(cards))
+	for i, rankCard := range rank.Cards {
+		for idx, card := range cards {
+			if used[idx] || card != rankCard {
+				continue
+			}
+			used[idx] = true
+			if i < made {
+				result.Indices = append(result.Indices, idx)
+			} else {
+				result.Kickers = append(result.Kickers, idx)
+			}
+			break
+		}
+	}
+	return result
+}
+
+// IsContributing 手牌中第 idx 張牌是否為組成牌型的牌
+func (h HandResult) IsContributing(idx int) bool {
+	for _, i := range h.Indices {
+		if i == idx {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		hand     string
+		expected HandType
+		indices  []int
+		kickers  []int
+	}{
+		{"Kd 9c 2s 9h 5c", Pair, []int{1, 3}, []int{0, 4, 2}},
+		{"9c Kh 9d 2s Ks", TwoPair, []int{1, 4, 0, 2}, []int{3}},
+		{"7c 7d 3h 7s Ah", ThreeOfAKind, []int{0, 1, 3}, []int{4, 2}},
+		// 第一張牌也要能被找到(舊的 GetStraightIndices 把索引0當成不存在)
+		{"Ac 2d 3h 4s 5c", Straight, []int{4, 3, 2, 1, 0}, []int{}},
+		{"Kd 8s 7s 6s 5s 4s Ks", StraightFlush, []int{1, 2, 3, 4, 5}, []int{}},
+		{"Ts Js Qs Ks As 2c 2d", RoyalFlush, []int{4, 3, 2, 1, 0}, []int{}},
+		{"2c 9h 4d Jd 6s", HighCard, []int{3}, []int{1, 4, 2, 0}},
+		{"5s 5d 5h Qc Qd 9c 5c", FourOfAKind, []int{0, 1, 2, 6}, []int{3}},
+	}
+
+	for _, tt := range tests {
+		result := Explain(MustParseHand(tt.hand))
+		if result.Type != tt.expected {
+			t.Errorf("%s: got %v, expected %v", tt.hand, result.Type.ToString(), tt.expected.ToString())
+		}
+		if !reflect.DeepEqual(result.Indices, tt.indices) {
+			t.Errorf("%s: got indices %v, expected %v", tt.hand, result.Indices, tt.indices)
+		}
+		if !reflect.DeepEqual(result.Kickers, tt.kickers) {
+			t.Errorf("%s: got kickers %v, expected %v", tt.hand, result.Kickers, tt.kickers)
+		}
+	}
+}
+
+func TestExplainWild(t *testing.T) {
+	result := JokerPokerRules.Explain(MustParseHand("JK 8h 8c Ks 2d"))
+	if result.Type != ThreeOfAKind || !reflect.DeepEqual(result.Indices, []int{1, 2, 0}) || !reflect.DeepEqual(result.Kickers, []int{3, 4}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if !result.IsContributing(0) || result.IsContributing(3) {
+		t.Errorf("Expected the joker to contribute and the king to be a kicker")
+	}
+}
+
+func TestGetStraightIndicesPositions(t *testing.T) {
+	hand := MustParseHand("5c 4d 3h 2s Ac")
+	if indices := GetStraightIndices(hand); !reflect.DeepEqual(indices, []int{4, 3, 2, 1, 0}) {
+		t.Errorf("Unexpected straight indices %v", indices)
+	}
+
+	// 同花順的索引要對應原本手牌的位置, 不是同花色排序後的位置
+	hand = MustParseHand("9h 2c Kh Jh Qh Th")
+	if indices := GetStraightFlushIndices(hand); !reflect.DeepEqual(indices, []int{0, 5, 3, 4, 2}) {
+		t.Errorf("Unexpected straight flush indices %v", indices)
+	}
+	if GetStraightFlushIndices(MustParseHand("9h 2c Kh Jh Qs Th")) != nil {
+		t.Errorf("Expected no straight flush")
+	}
+}
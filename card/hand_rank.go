@@ -0,0 +1,336 @@
+package card
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A 在比大小時視為最大的點數
+const aceHigh = 14
+
+// HandRank 一手牌完整的評比結果, 可以直接拿來比大小
+type HandRank struct {
+	Type  HandType // 牌型
+	Ranks []int    // 依序比大小的點數(A=14, A2345順子為5), 先比組成牌型的點數再比踢腳
+	Cards []*Card  // 實際使用的最多5張牌, 組成牌型的牌在前, 踢腳在後
+}
+
+func newHandRank(handType HandType, ranks []int, cards []*Card) HandRank {
+	return HandRank{
+		Type:  handType,
+		Ranks: ranks,
+		Cards: cards,
+	}
+}
+
+// Compare 比較兩手牌, 大於 other 回傳 1, 小於回傳 -1, 一樣大回傳 0
+func (h HandRank) Compare(other HandRank) int {
+	if h.Type != other.Type {
+		if h.Type > other.Type {
+			return 1
+		}
+		return -1
+	}
+	for i := 0; i < len(h.Ranks) && i < len(other.Ranks); i++ {
+		if h.Ranks[i] != other.Ranks[i] {
+			if h.Ranks[i] > other.Ranks[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// Less 是否小於 other
+func (h HandRank) Less(other HandRank) bool {
+	return h.Compare(other) < 0
+}
+
+func (h HandRank) ToString() string {
+	ranks := make([]string, len(h.Ranks))
+	for i, rank := range h.Ranks {
+		ranks[i] = rankName(rank)
+	}
+	return fmt.Sprintf("%s(%s)", h.Type.ToString(), strings.Join(ranks, ","))
+}
+
+func rankName(rank int) string {
+	switch rank {
+	case aceHigh:
+		return "A"
+	case 13:
+		return "K"
+	case 12:
+		return "Q"
+	case 11:
+		return "J"
+	default:
+		return fmt.Sprintf("%d", rank)
+	}
+}
+
+// 比大小用的點數, A 為 14
+func rankValue(number int) int {
+	if number == 1 {
+		return aceHigh
+	}
+	return number
+}
+
+// Evaluate 從傳入的牌中找出最大的5張組合(不足5張則全部使用), 回傳完整的評比結果
+// 鬼牌一律視為百搭, 其他百搭規則或牌型設定請使用 Rules.Evaluate
+func Evaluate(cards []*Card) HandRank {
+	return StandardRules.Evaluate(cards)
+}
+
+// 一次評估用到的資料, naturals 依點數由大到小排序, wilds 為百搭牌
+type evaluation struct {
+	rules     *Rules
+	straights []straightWindow
+	naturals  []*Card
+	wilds     []*Card
+	byRank    [aceHigh + 1][]*Card
+	bySuit    map[SuitType][]*Card
+}
+
+func evaluate(rules *Rules, naturals, wilds []*Card) HandRank {
+	e := &evaluation{
+		rules:     rules,
+		straights: rules.straights(),
+		naturals:  make([]*Card, len(naturals)),
+		wilds:     wilds,
+		bySuit:    make(map[SuitType][]*Card),
+	}
+	copy(e.naturals, naturals)
+	sort.SliceStable(e.naturals, func(i, j int) bool {
+		return rankValue(e.naturals[i].Number) > rankValue(e.naturals[j].Number)
+	})
+	for _, card := range e.naturals {
+		rank := rankValue(card.Number)
+		e.byRank[rank] = append(e.byRank[rank], card)
+		e.bySuit[card.Suit] = append(e.bySuit[card.Suit], card)
+	}
+
+	straightFlush, hasStraightFlush := e.evalStraightFlush()
+	if hasStraightFlush && straightFlush.Ranks[0] == aceHigh && e.enabled(RoyalFlush) {
+		straightFlush.Type = RoyalFlush
+		return straightFlush
+	}
+	if rank, ok := e.evalSets(5, 0, FiveOfAKind); ok {
+		return rank
+	}
+	if hasStraightFlush {
+		return straightFlush
+	}
+	if rank, ok := e.evalSets(4, 0, FourOfAKind); ok {
+		return rank
+	}
+	if rank, ok := e.evalSets(3, 2, FullHouse); ok {
+		return rank
+	}
+	if rank, ok := e.evalFlush(); ok {
+		return rank
+	}
+	if e.enabled(Straight) {
+		if straight, high := e.findStraight(e.naturals); straight != nil {
+			return newHandRank(Straight, []int{high}, straight)
+		}
+	}
+	if rank, ok := e.evalSets(3, 0, ThreeOfAKind); ok {
+		return rank
+	}
+	if rank, ok := e.evalSets(2, 2, TwoPair); ok {
+		return rank
+	}
+	if rank, ok := e.evalSets(2, 0, Pair); ok {
+		return rank
+	}
+	used := e.naturals
+	if len(used) > 5 {
+		used = used[:5]
+	}
+	ranks := make([]int, len(used))
+	for i, card := range used {
+		ranks[i] = rankValue(card.Number)
+	}
+	return newHandRank(HighCard, ranks, append([]*Card{}, used...))
+}
+
+func (e *evaluation) enabled(handType HandType) bool {
+	return e.rules.IsEnabled(handType)
+}
+
+// 找出最大的同點數組合, first 為第一組的張數, second 為第二組的張數(0表示不需要), 不足的張數用百搭補, 其餘補踢腳
+func (e *evaluation) evalSets(first, second int, handType HandType) (HandRank, bool) {
+	if !e.enabled(handType) {
+		return HandRank{}, false
+	}
+	for firstRank := aceHigh; firstRank >= 2; firstRank-- {
+		firstWilds, ok := e.wildsNeeded(firstRank, first, len(e.wilds))
+		if !ok {
+			continue
+		}
+		ranks := []int{firstRank}
+		used := e.setCards(firstRank, first, e.wilds[:firstWilds])
+
+		if second > 0 {
+			secondRank, secondWilds := 0, 0
+			for rank := aceHigh; rank >= 2; rank-- {
+				if rank == firstRank {
+					continue
+				}
+				if secondWilds, ok = e.wildsNeeded(rank, second, len(e.wilds)-firstWilds); ok {
+					secondRank = rank
+					break
+				}
+			}
+			if secondRank == 0 {
+				continue
+			}
+			ranks = append(ranks, secondRank)
+			used = append(used, e.setCards(secondRank, second, e.wilds[firstWilds:firstWilds+secondWilds])...)
+		}
+
+		for _, card := range e.naturals {
+			if len(used) >= 5 {
+				break
+			}
+			rank := rankValue(card.Number)
+			if rank == ranks[0] || (second > 0 && rank == ranks[1]) {
+				continue
+			}
+			ranks = append(ranks, rank)
+			used = append(used, card)
+		}
+		return newHandRank(handType, ranks, used), true
+	}
+	return HandRank{}, false
+}
+
+// 某個點數湊滿 size 張需要幾張百搭, 超過 maxWilds 時 ok 回傳 false
+func (e *evaluation) wildsNeeded(rank, size, maxWilds int) (int, bool) {
+	need := size - len(e.byRank[rank])
+	if need < 0 {
+		need = 0
+	}
+	return need, need <= maxWilds
+}
+
+func (e *evaluation) setCards(rank, size int, wilds []*Card) []*Card {
+	naturals := e.byRank[rank]
+	if len(naturals) > size {
+		naturals = naturals[:size]
+	}
+	used := append([]*Card{}, naturals...)
+	return append(used, wilds...)
+}
+
+func (e *evaluation) evalStraightFlush() (HandRank, bool) {
+	if !e.enabled(StraightFlush) && !e.enabled(RoyalFlush) {
+		return HandRank{}, false
+	}
+	var best HandRank
+	found := false
+	for _, suitCards := range e.bySuit {
+		if len(suitCards)+len(e.wilds) < 5 {
+			continue
+		}
+		straight, high := e.findStraight(suitCards)
+		if straight == nil {
+			continue
+		}
+		if high != aceHigh && !e.enabled(StraightFlush) {
+			continue
+		}
+		rank := newHandRank(StraightFlush, []int{high}, straight)
+		if !found || best.Less(rank) {
+			best = rank
+			found = true
+		}
+	}
+	return best, found
+}
+
+func (e *evaluation) evalFlush() (HandRank, bool) {
+	if !e.enabled(Flush) {
+		return HandRank{}, false
+	}
+	var best HandRank
+	found := false
+	for _, suitCards := range e.bySuit {
+		if len(suitCards)+len(e.wilds) < 5 {
+			continue
+		}
+		// 百搭依序當作這個花色缺少的最大點數, 再跟原本的牌一起取最大的5張
+		var present [aceHigh + 1]bool
+		for _, card := range suitCards {
+			present[rankValue(card.Number)] = true
+		}
+		ranks := make([]int, 0, 5)
+		used := make([]*Card, 0, 5)
+		natural, wild := 0, 0
+		for len(used) < 5 {
+			wildRank := 0
+			if wild < len(e.wilds) {
+				for rank := aceHigh; rank >= 2; rank-- {
+					if !present[rank] {
+						wildRank = rank
+						break
+					}
+				}
+			}
+			naturalRank := 0
+			if natural < len(suitCards) {
+				naturalRank = rankValue(suitCards[natural].Number)
+			}
+			if wildRank > naturalRank {
+				present[wildRank] = true
+				ranks = append(ranks, wildRank)
+				used = append(used, e.wilds[wild])
+				wild++
+			} else {
+				ranks = append(ranks, naturalRank)
+				used = append(used, suitCards[natural])
+				natural++
+			}
+		}
+		rank := newHandRank(Flush, ranks, used)
+		if !found || best.Less(rank) {
+			best = rank
+			found = true
+		}
+	}
+	return best, found
+}
+
+// 從已依點數由大到小排序的牌中找出最大的順子(缺的點數用百搭補), 回傳由大到小的5張牌與比大小用的點數, 找不到回傳 nil
+func (e *evaluation) findStraight(sorted []*Card) ([]*Card, int) {
+	var byRank [aceHigh + 1]*Card
+	for _, card := range sorted {
+		rank := rankValue(card.Number)
+		if byRank[rank] == nil {
+			byRank[rank] = card
+		}
+	}
+
+	for _, window := range e.straights {
+		straight := make([]*Card, 0, 5)
+		wild := 0
+		for _, rank := range window.ranks {
+			if byRank[rank] != nil {
+				straight = append(straight, byRank[rank])
+			} else if wild < len(e.wilds) {
+				straight = append(straight, e.wilds[wild])
+				wild++
+			} else {
+				break
+			}
+		}
+		if len(straight) == 5 {
+			return straight, window.high
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,74 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testHand(specs ...[2]int) []*Card {
+	cards := make([]*Card, len(specs))
+	for i, spec := range specs {
+		cards[i] = NewCard(SuitType(spec[0]), spec[1])
+	}
+	return cards
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cards    []*Card
+		expected HandType
+		ranks    []int
+	}{
+		{"HighCard", testHand([2]int{0, 1}, [2]int{1, 9}, [2]int{2, 7}, [2]int{3, 4}, [2]int{0, 2}), HighCard, []int{14, 9, 7, 4, 2}},
+		{"Pair", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 13}, [2]int{3, 4}, [2]int{0, 2}), Pair, []int{5, 13, 4, 2}},
+		{"TwoPair", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 13}, [2]int{3, 13}, [2]int{0, 2}), TwoPair, []int{13, 5, 2}},
+		{"ThreeOfAKind", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 5}, [2]int{3, 4}, [2]int{0, 2}), ThreeOfAKind, []int{5, 4, 2}},
+		{"Straight", testHand([2]int{0, 10}, [2]int{1, 11}, [2]int{2, 12}, [2]int{3, 13}, [2]int{0, 1}), Straight, []int{14}},
+		{"Wheel", testHand([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 4}, [2]int{0, 5}), Straight, []int{5}},
+		{"Flush", testHand([2]int{2, 1}, [2]int{2, 9}, [2]int{2, 7}, [2]int{2, 4}, [2]int{2, 2}), Flush, []int{14, 9, 7, 4, 2}},
+		{"FullHouse", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 5}, [2]int{3, 4}, [2]int{0, 4}), FullHouse, []int{5, 4}},
+		{"FourOfAKind", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 5}, [2]int{3, 5}, [2]int{0, 4}), FourOfAKind, []int{5, 4}},
+		{"StraightFlush", testHand([2]int{3, 9}, [2]int{3, 10}, [2]int{3, 11}, [2]int{3, 12}, [2]int{3, 13}), StraightFlush, []int{13}},
+		// 7張牌時要取最大的5張, 三條加順子要算順子
+		{"SevenStraightOverTrips", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 5}, [2]int{3, 6}, [2]int{0, 7}, [2]int{1, 8}, [2]int{2, 9}), Straight, []int{9}},
+		{"SevenFlushOverStraight", testHand([2]int{1, 2}, [2]int{1, 5}, [2]int{1, 6}, [2]int{1, 7}, [2]int{1, 8}, [2]int{0, 9}, [2]int{2, 4}), Flush, []int{8, 7, 6, 5, 2}},
+		{"SevenTwoTrips", testHand([2]int{0, 5}, [2]int{1, 5}, [2]int{2, 5}, [2]int{3, 9}, [2]int{0, 9}, [2]int{1, 9}, [2]int{2, 2}), FullHouse, []int{9, 5}},
+	}
+
+	for _, tt := range tests {
+		rank := Evaluate(tt.cards)
+		if rank.Type != tt.expected {
+			t.Errorf("%s: got %v, expected %v", tt.name, rank.Type.ToString(), tt.expected.ToString())
+		}
+		if !reflect.DeepEqual(rank.Ranks, tt.ranks) {
+			t.Errorf("%s: got ranks %v, expected %v", tt.name, rank.Ranks, tt.ranks)
+		}
+		if len(tt.cards) >= 5 && len(rank.Cards) != 5 {
+			t.Errorf("%s: expected 5 cards, got %d", tt.name, len(rank.Cards))
+		}
+	}
+}
+
+func TestHandRankCompare(t *testing.T) {
+	acesPair := Evaluate(testHand([2]int{0, 1}, [2]int{1, 1}, [2]int{2, 13}, [2]int{3, 12}, [2]int{0, 11}))
+	twoPair := Evaluate(testHand([2]int{0, 2}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 3}, [2]int{0, 4}))
+	kingsPair := Evaluate(testHand([2]int{0, 13}, [2]int{1, 13}, [2]int{2, 1}, [2]int{3, 12}, [2]int{0, 11}))
+	kingsPairOtherSuits := Evaluate(testHand([2]int{2, 13}, [2]int{3, 13}, [2]int{0, 1}, [2]int{1, 12}, [2]int{2, 11}))
+
+	if !acesPair.Less(twoPair) {
+		t.Errorf("Expected two pair to beat a pair of aces")
+	}
+	if !kingsPair.Less(acesPair) {
+		t.Errorf("Expected a pair of aces to beat a pair of kings")
+	}
+	if kingsPair.Compare(kingsPairOtherSuits) != 0 {
+		t.Errorf("Expected equal hands to compare as 0")
+	}
+
+	wheel := Evaluate(testHand([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 4}, [2]int{0, 5}))
+	sixHigh := Evaluate(testHand([2]int{0, 6}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 4}, [2]int{0, 5}))
+	if !wheel.Less(sixHigh) {
+		t.Errorf("Expected A2345 to be the lowest straight")
+	}
+}
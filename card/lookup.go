@@ -0,0 +1,279 @@
+package card
+
+import "sort"
+
+// 查表式的牌型評估(Cactus Kev 5張牌評估法), 6、7張牌則取所有5張組合中最大的
+//
+// 每張牌編碼成 32 bits:
+//
+//	xxxbbbbb bbbbbbbb cdhsrrrr xxpppppp
+//	b: 點數 bit(2~A), cdhs: 花色 bit, r: 點數(2=0 ~ A=12), p: 點數對應的質數
+//
+// 5張牌共有 7462 種等價類別, 1 為最大(同花大順), 7462 為最小(75432高牌)
+// 查表過程只用到堆疊上的陣列, 不會配置任何記憶體
+
+var rankPrimes = [13]uint32{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41}
+
+const rankBitsSize = 0x1F00 + 1
+
+var (
+	flushTable    [rankBitsSize]uint16 // 同花, 以點數 bits 為索引
+	unique5Table  [rankBitsSize]uint16 // 5張不同點數的非同花(順子、高牌)
+	productKeys   []uint32             // 有重複點數時以質數乘積查表, 由小到大排序
+	productValues []uint16
+	classBounds   []classBound // 由大到小各牌型最小(數字最大)的類別
+
+	// 6、7張牌中所有5張組合的索引
+	sixCardCombos   [][5]uint8
+	sevenCardCombos [][5]uint8
+)
+
+type classBound struct {
+	lastClass uint16
+	handType  HandType
+}
+
+func init() {
+	buildLookupTables()
+	sixCardCombos = buildFiveCardCombos(6)
+	sevenCardCombos = buildFiveCardCombos(7)
+}
+
+func buildLookupTables() {
+	class := uint16(0)
+	next := func() uint16 {
+		class++
+		return class
+	}
+	products := make(map[uint32]uint16)
+
+	// 由大到小的10種順子, 最後一個是 A2345
+	straights := make([]uint32, 0, 10)
+	for high := 12; high >= 4; high-- {
+		straights = append(straights, 0x1F<<uint(high-4))
+	}
+	straights = append(straights, 0x100F)
+	isStraight := make(map[uint32]bool)
+	for _, mask := range straights {
+		isStraight[mask] = true
+	}
+
+	// 由大到小, 5張點數都不同且不是順子的點數 bits
+	var distinct []uint32
+	for a := 12; a >= 4; a-- {
+		for b := a - 1; b >= 3; b-- {
+			for c := b - 1; c >= 2; c-- {
+				for d := c - 1; d >= 1; d-- {
+					for e := d - 1; e >= 0; e-- {
+						mask := uint32(1)<<uint(a) | 1<<uint(b) | 1<<uint(c) | 1<<uint(d) | 1<<uint(e)
+						if !isStraight[mask] {
+							distinct = append(distinct, mask)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	bound := func(handType HandType) {
+		classBounds = append(classBounds, classBound{lastClass: class, handType: handType})
+	}
+
+	for _, mask := range straights {
+		flushTable[mask] = next()
+		if class == 1 {
+			bound(RoyalFlush)
+		}
+	}
+	bound(StraightFlush)
+
+	for quad := 12; quad >= 0; quad-- {
+		for kicker := 12; kicker >= 0; kicker-- {
+			if kicker != quad {
+				products[pow(rankPrimes[quad], 4)*rankPrimes[kicker]] = next()
+			}
+		}
+	}
+	bound(FourOfAKind)
+
+	for trip := 12; trip >= 0; trip-- {
+		for pair := 12; pair >= 0; pair-- {
+			if pair != trip {
+				products[pow(rankPrimes[trip], 3)*pow(rankPrimes[pair], 2)] = next()
+			}
+		}
+	}
+	bound(FullHouse)
+
+	for _, mask := range distinct {
+		flushTable[mask] = next()
+	}
+	bound(Flush)
+
+	for _, mask := range straights {
+		unique5Table[mask] = next()
+	}
+	bound(Straight)
+
+	for trip := 12; trip >= 0; trip-- {
+		for k1 := 12; k1 >= 0; k1-- {
+			for k2 := k1 - 1; k2 >= 0; k2-- {
+				if k1 != trip && k2 != trip {
+					products[pow(rankPrimes[trip], 3)*rankPrimes[k1]*rankPrimes[k2]] = next()
+				}
+			}
+		}
+	}
+	bound(ThreeOfAKind)
+
+	for high := 12; high >= 0; high-- {
+		for low := high - 1; low >= 0; low-- {
+			for kicker := 12; kicker >= 0; kicker-- {
+				if kicker != high && kicker != low {
+					products[pow(rankPrimes[high], 2)*pow(rankPrimes[low], 2)*rankPrimes[kicker]] = next()
+				}
+			}
+		}
+	}
+	bound(TwoPair)
+
+	for pair := 12; pair >= 0; pair-- {
+		for k1 := 12; k1 >= 0; k1-- {
+			for k2 := k1 - 1; k2 >= 0; k2-- {
+				for k3 := k2 - 1; k3 >= 0; k3-- {
+					if k1 != pair && k2 != pair && k3 != pair {
+						products[pow(rankPrimes[pair], 2)*rankPrimes[k1]*rankPrimes[k2]*rankPrimes[k3]] = next()
+					}
+				}
+			}
+		}
+	}
+	bound(Pair)
+
+	for _, mask := range distinct {
+		unique5Table[mask] = next()
+	}
+	bound(HighCard)
+
+	productKeys = make([]uint32, 0, len(products))
+	for key := range products {
+		productKeys = append(productKeys, key)
+	}
+	sort.Slice(productKeys, func(i, j int) bool {
+		return productKeys[i] < productKeys[j]
+	})
+	productValues = make([]uint16, len(productKeys))
+	for i, key := range productKeys {
+		productValues[i] = products[key]
+	}
+}
+
+func pow(base uint32, exp int) uint32 {
+	result := uint32(1)
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
+
+// 產生 n 張牌中所有5張組合的索引
+func buildFiveCardCombos(n int) [][5]uint8 {
+	var combos [][5]uint8
+	var combo [5]uint8
+	var helper func(start, depth int)
+	helper = func(start, depth int) {
+		if depth == 5 {
+			combos = append(combos, combo)
+			return
+		}
+		for i := start; i <= n-(5-depth); i++ {
+			combo[depth] = uint8(i)
+			helper(i+1, depth+1)
+		}
+	}
+	helper(0, 0)
+	return combos
+}
+
+// 將牌編碼成查表用的 32 bits
+func encodeCard(suit SuitType, number int) uint32 {
+	rank := uint32(rankValue(number) - 2)
+	return 1<<(16+rank) | 0x1000<<uint32(suit) | rank<<8 | rankPrimes[rank]
+}
+
+// 5張已編碼的牌的等價類別
+func fiveCardClass(c1, c2, c3, c4, c5 uint32) uint16 {
+	rankBits := (c1 | c2 | c3 | c4 | c5) >> 16
+	if c1&c2&c3&c4&c5&0xF000 != 0 {
+		return flushTable[rankBits]
+	}
+	if class := unique5Table[rankBits]; class != 0 {
+		return class
+	}
+	product := (c1 & 0xFF) * (c2 & 0xFF) * (c3 & 0xFF) * (c4 & 0xFF) * (c5 & 0xFF)
+	low, high := 0, len(productKeys)-1
+	for low <= high {
+		mid := (low + high) / 2
+		switch {
+		case productKeys[mid] < product:
+			low = mid + 1
+		case productKeys[mid] > product:
+			high = mid - 1
+		default:
+			return productValues[mid]
+		}
+	}
+	return 0
+}
+
+// 5~7張已編碼的牌中最大的5張組合的等價類別
+func bestClass(encoded []uint32) uint16 {
+	var combos [][5]uint8
+	switch len(encoded) {
+	case 5:
+		return fiveCardClass(encoded[0], encoded[1], encoded[2], encoded[3], encoded[4])
+	case 6:
+		combos = sixCardCombos
+	default:
+		combos = sevenCardCombos
+	}
+	best := uint16(0xFFFF)
+	for _, combo := range combos {
+		class := fiveCardClass(encoded[combo[0]], encoded[combo[1]], encoded[combo[2]], encoded[combo[3]], encoded[combo[4]])
+		if class < best {
+			best = class
+		}
+	}
+	return best
+}
+
+// 以查表取得等價類別, 只支援標準52張牌中不重複的5~7張牌, 不支援時 ok 回傳 false
+func handClass(cards []*Card) (uint16, bool) {
+	if len(cards) < 5 || len(cards) > 7 {
+		return 0, false
+	}
+	var encoded [7]uint32
+	var seen uint64
+	for i, card := range cards {
+		if card.Suit < Clubs || card.Suit > Spades || card.Number < 1 || card.Number > 13 {
+			return 0, false
+		}
+		bit := uint64(1) << uint(int(card.Suit)*13+card.Number-1)
+		if seen&bit != 0 {
+			return 0, false
+		}
+		seen |= bit
+		encoded[i] = encodeCard(card.Suit, card.Number)
+	}
+	return bestClass(encoded[:len(cards)]), true
+}
+
+// 等價類別對應的牌型
+func classHandType(class uint16) HandType {
+	for _, bound := range classBounds {
+		if class <= bound.lastClass {
+			return bound.handType
+		}
+	}
+	return HighCard
+}
@@ -0,0 +1,121 @@
+package card
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func fullDeck() []*Card {
+	deck := make([]*Card, 0, 52)
+	for suit := 0; suit < 4; suit++ {
+		for number := 1; number <= 13; number++ {
+			deck = append(deck, NewCard(SuitType(suit), number))
+		}
+	}
+	return deck
+}
+
+func TestLookupAllFiveCardHands(t *testing.T) {
+	expected := map[HandType]int{
+		RoyalFlush:    4,
+		StraightFlush: 36,
+		FourOfAKind:   624,
+		FullHouse:     3744,
+		Flush:         5108,
+		Straight:      10200,
+		ThreeOfAKind:  54912,
+		TwoPair:       123552,
+		Pair:          1098240,
+		HighCard:      1302540,
+	}
+
+	deck := fullDeck()
+	counts := make(map[HandType]int)
+	hand := make([]*Card, 5)
+	for a := 0; a < 52; a++ {
+		for b := a + 1; b < 52; b++ {
+			for c := b + 1; c < 52; c++ {
+				for d := c + 1; d < 52; d++ {
+					for e := d + 1; e < 52; e++ {
+						hand[0], hand[1], hand[2], hand[3], hand[4] = deck[a], deck[b], deck[c], deck[d], deck[e]
+						counts[GetHandType(hand)]++
+					}
+				}
+			}
+		}
+	}
+
+	for handType, count := range expected {
+		if counts[handType] != count {
+			t.Errorf("%s: got %d, expected %d", handType.ToString(), counts[handType], count)
+		}
+	}
+}
+
+func TestLookupMatchesEvaluate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	deck := fullDeck()
+	for i := 0; i < 20000; i++ {
+		rng.Shuffle(len(deck), func(i, j int) {
+			deck[i], deck[j] = deck[j], deck[i]
+		})
+		n := 5 + i%3
+		hand := deck[:n]
+		class, ok := handClass(hand)
+		if !ok {
+			t.Fatalf("Expected lookup to support %d cards", n)
+		}
+		rank := Evaluate(hand)
+		if classHandType(class) != rank.Type {
+			t.Errorf("Hand %v: lookup type %v, evaluate type %v", hand, classHandType(class).ToString(), rank.Type.ToString())
+		}
+	}
+}
+
+func TestLookupClassOrdering(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	deck := fullDeck()
+	for i := 0; i < 20000; i++ {
+		rng.Shuffle(len(deck), func(i, j int) {
+			deck[i], deck[j] = deck[j], deck[i]
+		})
+		first, second := deck[:5], deck[5:10]
+		firstClass, _ := handClass(first)
+		secondClass, _ := handClass(second)
+		cmp := Evaluate(first).Compare(Evaluate(second))
+		if (firstClass < secondClass) != (cmp > 0) || (firstClass == secondClass) != (cmp == 0) {
+			t.Errorf("Class order %d vs %d disagrees with Compare %d", firstClass, secondClass, cmp)
+		}
+	}
+}
+
+func TestGetHandTypeZeroAllocs(t *testing.T) {
+	deck := fullDeck()
+	for n := 5; n <= 7; n++ {
+		hand := deck[10 : 10+n]
+		allocs := testing.AllocsPerRun(100, func() {
+			GetHandType(hand)
+		})
+		if allocs != 0 {
+			t.Errorf("%d cards: expected 0 allocs, got %v", n, allocs)
+		}
+	}
+}
+
+func BenchmarkGetHandType7(b *testing.B) {
+	deck := fullDeck()
+	hand := []*Card{deck[0], deck[14], deck[27], deck[40], deck[5], deck[19], deck[33]}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetHandType(hand)
+	}
+}
+
+func BenchmarkEvaluate7(b *testing.B) {
+	deck := fullDeck()
+	hand := []*Card{deck[0], deck[14], deck[27], deck[40], deck[5], deck[19], deck[33]}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Evaluate(hand)
+	}
+}
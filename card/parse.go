@@ -0,0 +1,257 @@
+package card
+
+import (
+	"fmt"
+	"math-discard-card/i18n"
+	"strings"
+	"unicode"
+)
+
+// 牌的文字表示法, 點數在前花色在後, 例如 "As"、"Td"、"2c"
+var numberNotations = [...]string{1: "A", 2: "2", 3: "3", 4: "4", 5: "5", 6: "6", 7: "7", 8: "8", 9: "9", 10: "T", 11: "J", 12: "Q", 13: "K"}
+
+var suitNotations = [...]string{Clubs: "c", Diamonds: "d", Hearts: "h", Spades: "s"}
+
+var suitNames = [...]string{Clubs: "clubs", Diamonds: "diamonds", Hearts: "hearts", Spades: "spades", Joker: "joker"}
+
+// 可以當作花色的字元
+var suitRunes = map[rune]SuitType{
+	'c': Clubs, 'C': Clubs, '♣': Clubs, '♧': Clubs,
+	'd': Diamonds, 'D': Diamonds, '♦': Diamonds, '♢': Diamonds,
+	'h': Hearts, 'H': Hearts, '♥': Hearts, '♡': Hearts,
+	's': Spades, 'S': Spades, '♠': Spades, '♤': Spades,
+}
+
+// ParseSuit 解析花色, 接受 "s"、"S"、"♠"、"spades" 等寫法
+func ParseSuit(str string) (SuitType, error) {
+	str = strings.TrimSpace(str)
+	for suit, name := range suitNames {
+		if strings.EqualFold(str, name) {
+			return SuitType(suit), nil
+		}
+	}
+	runes := []rune(str)
+	if len(runes) == 1 {
+		if suit, ok := suitRunes[runes[0]]; ok {
+			return suit, nil
+		}
+	}
+	return 0, i18n.Errorf("error.parse_suit", str)
+}
+
+// ParseCard 解析單張牌, 接受 "As"、"Td"、"10S"、"♠10"、"♣1"(Card.ToString 的格式)、"JK"(鬼牌) 等寫法
+func ParseCard(str string) (*Card, error) {
+	runes := []rune(strings.TrimSpace(str))
+	card, next, err := parseCardAt(runes, 0)
+	if err != nil {
+		return nil, err
+	}
+	if next != len(runes) {
+		return nil, i18n.Errorf("error.parse_card", str)
+	}
+	return card, nil
+}
+
+// ParseHand 解析多張牌, 可以用空白或逗號分隔, 也可以直接相連, 例如 "As Kd"、"♠10,♥A"、"AsKdQh"
+func ParseHand(str string) ([]*Card, error) {
+	runes := []rune(str)
+	cards := []*Card{}
+	for pos := 0; pos < len(runes); {
+		if isHandSeparator(runes[pos]) {
+			pos++
+			continue
+		}
+		card, next, err := parseCardAt(runes, pos)
+		if err != nil {
+			return nil, i18n.Errorf("error.parse_hand", str, err)
+		}
+		cards = append(cards, card)
+		pos = next
+	}
+	return cards, nil
+}
+
+// MustParseHand 同 ParseHand, 解析失敗時直接 panic, 給寫死的牌組或測試資料使用
+func MustParseHand(str string) []*Card {
+	cards, err := ParseHand(str)
+	if err != nil {
+		panic(err)
+	}
+	return cards
+}
+
+func isHandSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ',' || r == '[' || r == ']'
+}
+
+// 從 pos 開始解析一張牌, 回傳牌與下一個要解析的位置
+func parseCardAt(runes []rune, pos int) (*Card, int, error) {
+	if pos >= len(runes) {
+		return nil, pos, i18n.Errorf("error.missing_card")
+	}
+	// 鬼牌, 例如 "JK"、"Joker2"、"🃏1", 沒有編號時為第1張
+	if next, ok := matchJokerPrefix(runes, pos); ok {
+		number := 0
+		for next < len(runes) && unicode.IsDigit(runes[next]) {
+			number = number*10 + int(runes[next]-'0')
+			next++
+		}
+		if number == 0 {
+			number = 1
+		}
+		return NewJoker(number), next, nil
+	}
+	// 花色在前, 例如 "♠10"
+	if suit, ok := suitRunes[runes[pos]]; ok && !unicode.IsLetter(runes[pos]) {
+		number, next, err := parseNumberAt(runes, pos+1)
+		if err != nil {
+			return nil, pos, err
+		}
+		return NewCard(suit, number), next, nil
+	}
+	// 點數在前, 例如 "As"、"10S"
+	number, next, err := parseNumberAt(runes, pos)
+	if err != nil {
+		return nil, pos, err
+	}
+	if next >= len(runes) {
+		return nil, pos, i18n.Errorf("error.missing_suit", string(runes[pos:]))
+	}
+	suit, ok := suitRunes[runes[next]]
+	if !ok {
+		return nil, pos, i18n.Errorf("error.parse_suit", string(runes[next]))
+	}
+	return NewCard(suit, number), next + 1, nil
+}
+
+var jokerPrefixes = []string{"joker", "jk", "🃏"}
+
+func matchJokerPrefix(runes []rune, pos int) (int, bool) {
+	for _, prefix := range jokerPrefixes {
+		prefixRunes := []rune(prefix)
+		end := pos + len(prefixRunes)
+		if end <= len(runes) && strings.EqualFold(string(runes[pos:end]), prefix) {
+			return end, true
+		}
+	}
+	return pos, false
+}
+
+// 從 pos 開始解析點數, 接受 A、K、Q、J、T 與 1~13 的數字
+func parseNumberAt(runes []rune, pos int) (int, int, error) {
+	if pos >= len(runes) {
+		return 0, pos, i18n.Errorf("error.missing_number")
+	}
+	switch unicode.ToUpper(runes[pos]) {
+	case 'A':
+		return 1, pos + 1, nil
+	case 'K':
+		return 13, pos + 1, nil
+	case 'Q':
+		return 12, pos + 1, nil
+	case 'J':
+		return 11, pos + 1, nil
+	case 'T':
+		return 10, pos + 1, nil
+	}
+	number := 0
+	next := pos
+	for next < len(runes) && next-pos < 2 && unicode.IsDigit(runes[next]) {
+		number = number*10 + int(runes[next]-'0')
+		next++
+	}
+	if next == pos || number < 1 || number > 13 {
+		return 0, pos, i18n.Errorf("error.parse_number", string(runes[pos:]))
+	}
+	return number, next, nil
+}
+
+// Notation 牌的文字表示法, 例如 "As"、"Td", 鬼牌為 "JK"、"JK2"
+func (c *Card) Notation() string {
+	if c.IsJoker() {
+		if c.Number == 1 {
+			return "JK"
+		}
+		return fmt.Sprintf("JK%d", c.Number)
+	}
+	return numberNotations[c.Number] + suitNotations[c.Suit]
+}
+
+// MarshalText 實作 encoding.TextMarshaler, JSON 也會使用這個格式
+func (c Card) MarshalText() ([]byte, error) {
+	if c.Number < 1 || (c.Number > 13 && !c.IsJoker()) || c.Suit < Clubs || c.Suit > Joker {
+		return nil, i18n.Errorf("error.marshal_card", c)
+	}
+	return []byte(c.Notation()), nil
+}
+
+// UnmarshalText 實作 encoding.TextUnmarshaler
+func (c *Card) UnmarshalText(text []byte) error {
+	card, err := ParseCard(string(text))
+	if err != nil {
+		return err
+	}
+	*c = *card
+	return nil
+}
+
+// MarshalText 實作 encoding.TextMarshaler, 輸出 "spades" 等英文名稱
+func (s SuitType) MarshalText() ([]byte, error) {
+	if s < Clubs || s > Joker {
+		return nil, i18n.Errorf("error.marshal_suit", int(s))
+	}
+	return []byte(suitNames[s]), nil
+}
+
+// UnmarshalText 實作 encoding.TextUnmarshaler
+func (s *SuitType) UnmarshalText(text []byte) error {
+	suit, err := ParseSuit(string(text))
+	if err != nil {
+		return err
+	}
+	*s = suit
+	return nil
+}
+
+var handTypeNames = [...]string{
+	HighCard:      "HighCard",
+	Pair:          "Pair",
+	TwoPair:       "TwoPair",
+	ThreeOfAKind:  "ThreeOfAKind",
+	Straight:      "Straight",
+	Flush:         "Flush",
+	FullHouse:     "FullHouse",
+	FourOfAKind:   "FourOfAKind",
+	StraightFlush: "StraightFlush",
+	FiveOfAKind:   "FiveOfAKind",
+	RoyalFlush:    "RoyalFlush",
+}
+
+// ParseHandType 解析牌型的英文名稱, 不分大小寫並忽略底線與空白, 例如 "FullHouse"、"full_house"
+func ParseHandType(str string) (HandType, error) {
+	normalized := strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.TrimSpace(str))
+	for handType, name := range handTypeNames {
+		if strings.EqualFold(normalized, name) {
+			return HandType(handType), nil
+		}
+	}
+	return 0, i18n.Errorf("error.parse_hand_type", str)
+}
+
+// MarshalText 實作 encoding.TextMarshaler, 輸出 "FullHouse" 等英文名稱
+func (h HandType) MarshalText() ([]byte, error) {
+	if h < HighCard || h > RoyalFlush {
+		return nil, i18n.Errorf("error.marshal_hand_type", int(h))
+	}
+	return []byte(handTypeNames[h]), nil
+}
+
+// UnmarshalText 實作 encoding.TextUnmarshaler
+func (h *HandType) UnmarshalText(text []byte) error {
+	handType, err := ParseHandType(string(text))
+	if err != nil {
+		return err
+	}
+	*h = handType
+	return nil
+}
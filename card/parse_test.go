@@ -0,0 +1,144 @@
+package card
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseCard(t *testing.T) {
+	tests := []struct {
+		input    string
+		suit     SuitType
+		number   int
+		expected bool // Expecting no error
+	}{
+		{"As", Spades, 1, true},
+		{"Td", Diamonds, 10, true},
+		{"10S", Spades, 10, true},
+		{"♠10", Spades, 10, true},
+		{"♣1", Clubs, 1, true},
+		{"♥13", Hearts, 13, true},
+		{"2c", Clubs, 2, true},
+		{" kh ", Hearts, 13, true},
+		{"1x", 0, 0, false},
+		{"14s", 0, 0, false},
+		{"A", 0, 0, false},
+		{"AsK", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		card, err := ParseCard(tt.input)
+		if (err == nil) != tt.expected {
+			t.Errorf("%q: got error %v, expected success %v", tt.input, err, tt.expected)
+			continue
+		}
+		if err == nil && (card.Suit != tt.suit || card.Number != tt.number || card.Idx != NewCard(tt.suit, tt.number).Idx) {
+			t.Errorf("%q: got %v, expected suit %d number %d", tt.input, card.ToString(), tt.suit, tt.number)
+		}
+	}
+}
+
+func TestParseCardRoundTrip(t *testing.T) {
+	for _, card := range FullDeckSet.Cards() {
+		fromString, err := ParseCard(card.ToString())
+		if err != nil || fromString.Idx != card.Idx {
+			t.Errorf("ToString round trip failed for %v: %v", card.ToString(), err)
+		}
+		fromNotation, err := ParseCard(card.Notation())
+		if err != nil || fromNotation.Idx != card.Idx {
+			t.Errorf("Notation round trip failed for %v: %v", card.Notation(), err)
+		}
+	}
+}
+
+func TestParseHand(t *testing.T) {
+	expected := []int{NewCard(Spades, 1).Idx, NewCard(Diamonds, 13).Idx, NewCard(Hearts, 10).Idx}
+	for _, input := range []string{"As Kd Th", "As,Kd,Th", "AsKdTh", "[♠1] [♦13] [♥10]", "AS, 13d, 10H"} {
+		cards, err := ParseHand(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", input, err)
+			continue
+		}
+		if len(cards) != len(expected) {
+			t.Errorf("%q: expected %d cards, got %d", input, len(expected), len(cards))
+			continue
+		}
+		for i, card := range cards {
+			if card.Idx != expected[i] {
+				t.Errorf("%q: card %d got %v", input, i, card.ToString())
+			}
+		}
+	}
+
+	if _, err := ParseHand("As Kx"); err == nil {
+		t.Errorf("Expected error for invalid hand")
+	}
+}
+
+func TestCardJSON(t *testing.T) {
+	type fixture struct {
+		Hand []*Card  `json:"hand"`
+		Suit SuitType `json:"suit"`
+	}
+	input := fixture{Hand: MustParseHand("As Td 2c"), Suit: Hearts}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if string(data) != `{"hand":["As","Td","2c"],"suit":"hearts"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	var output fixture
+	if err := json.Unmarshal([]byte(`{"hand":["As","♦10","2C"],"suit":"♥"}`), &output); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if NewCardSet(output.Hand...) != NewCardSet(input.Hand...) || output.Suit != Hearts {
+		t.Errorf("Unexpected fixture: %+v", output)
+	}
+
+	if err := json.Unmarshal([]byte(`{"hand":["Zz"]}`), &output); err == nil {
+		t.Errorf("Expected error for invalid card")
+	}
+}
+
+func TestLegacyHandType(t *testing.T) {
+	// 舊版的牌型數字, 加入兩對後三條以上都往後移
+	tests := []struct {
+		legacy   int
+		expected HandType
+	}{
+		{0, HighCard},
+		{1, Pair},
+		{2, ThreeOfAKind},
+		{3, Straight},
+		{4, Flush},
+		{5, FullHouse},
+		{6, FourOfAKind},
+		{7, StraightFlush},
+	}
+
+	for _, tt := range tests {
+		got, err := LegacyHandType(tt.legacy)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", tt.legacy, err)
+		}
+		if got != tt.expected {
+			t.Errorf("%d: expected %s, got %s", tt.legacy, tt.expected.ToString(), got.ToString())
+		}
+		if text, _ := got.MarshalText(); string(text) != handTypeNames[tt.expected] {
+			t.Errorf("%d: expected name %s, got %s", tt.legacy, handTypeNames[tt.expected], text)
+		}
+	}
+	// 目前的數字與舊版不同, 不能直接轉型
+	if HandType(2) != TwoPair || StraightFlush != 8 || RoyalFlush != 10 {
+		t.Errorf("expected TwoPair=2, StraightFlush=8, RoyalFlush=10")
+	}
+	for _, n := range []int{-1, 8} {
+		if _, err := LegacyHandType(n); err == nil {
+			t.Errorf("%d: expected an error", n)
+		}
+	}
+}
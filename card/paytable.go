@@ -0,0 +1,133 @@
+package card
+
+import (
+	"encoding/json"
+	"math-discard-card/i18n"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PayMode 賠率的計算方式
+//
+// 賠率是點數而不是押注的倍數; 押注就是開局花費(GameCost), x to 1 退還的本金以 Paytable.Stake 記錄,
+// 遊戲與分析會以 WithStake 設成開局花費, 兩種方式都與實際的押注一致
+type PayMode string
+
+const (
+	PayFor PayMode = "for" // x for 1: 拿回 x 點(已含押注)
+	PayTo  PayMode = "to"  // x to 1: 贏得 x 點並退還押注, 共拿回 x + Stake 點
+)
+
+// Paytable 各牌型的賠率表, 可從 JSON/YAML 設定檔讀取
+type Paytable struct {
+	Version string           `json:"version" yaml:"version"` // 賠率表版本, 用來區分不同的賠率設定
+	Mode    PayMode          `json:"mode" yaml:"mode"`       // 賠率計算方式, 空白視為 PayFor
+	Pays    map[HandType]int `json:"pays" yaml:"pays"`       // 各牌型的賠率, 沒有列出的牌型不派彩
+	Stake   int              `json:"-" yaml:"-"`             // PayTo 派彩時退還的押注點數, 由 WithStake 依開局花費設定
+}
+
+// WithStake 押注為 stake 點的賠率表, 與原本的賠率表共用 Pays, 不修改原本的賠率表
+func (p *Paytable) WithStake(stake int) *Paytable {
+	if p.Stake == stake {
+		return p
+	}
+	paytable := *p
+	paytable.Stake = stake
+	return &paytable
+}
+
+// DefaultPaytable 以 HandType.GetOdds 建立的預設賠率表
+func DefaultPaytable() *Paytable {
+	pays := make(map[HandType]int)
+	for _, handType := range AllHandTypes {
+		if odds := handType.GetOdds(); odds > 0 {
+			pays[handType] = odds
+		}
+	}
+	return &Paytable{
+		Version: "default",
+		Mode:    PayFor,
+		Pays:    pays,
+	}
+}
+
+// LoadPaytable 依副檔名(.json、.yaml、.yml)讀取賠率表設定檔
+func LoadPaytable(path string) (*Paytable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("error.paytable_read", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParsePaytableJSON(data)
+	case ".yaml", ".yml":
+		return ParsePaytableYAML(data)
+	default:
+		return nil, i18n.Errorf("error.paytable_format", path)
+	}
+}
+
+// ParsePaytableJSON 解析 JSON 格式的賠率表並檢查內容
+func ParsePaytableJSON(data []byte) (*Paytable, error) {
+	paytable := &Paytable{}
+	if err := json.Unmarshal(data, paytable); err != nil {
+		return nil, i18n.Errorf("error.paytable_parse", err)
+	}
+	if err := paytable.Validate(); err != nil {
+		return nil, err
+	}
+	return paytable, nil
+}
+
+// ParsePaytableYAML 解析 YAML 格式的賠率表並檢查內容
+func ParsePaytableYAML(data []byte) (*Paytable, error) {
+	paytable := &Paytable{}
+	if err := yaml.Unmarshal(data, paytable); err != nil {
+		return nil, i18n.Errorf("error.paytable_parse", err)
+	}
+	if err := paytable.Validate(); err != nil {
+		return nil, err
+	}
+	return paytable, nil
+}
+
+// Validate 檢查賠率表內容是否合法, Mode 空白時會補上 PayFor
+func (p *Paytable) Validate() error {
+	if p.Version == "" {
+		return i18n.Errorf("error.paytable_version")
+	}
+	switch p.Mode {
+	case "":
+		p.Mode = PayFor
+	case PayFor, PayTo:
+	default:
+		return i18n.Errorf("error.paytable_mode", p.Version, string(p.Mode))
+	}
+	if len(p.Pays) == 0 {
+		return i18n.Errorf("error.paytable_empty", p.Version)
+	}
+	for handType, pay := range p.Pays {
+		if handType < HighCard || handType > RoyalFlush {
+			return i18n.Errorf("error.paytable_hand_type", p.Version, int(handType))
+		}
+		if pay < 0 {
+			return i18n.Errorf("error.paytable_negative", p.Version, handType, pay)
+		}
+	}
+	return nil
+}
+
+// Payout 玩家拿回的點數(含押注), PayTo 時加上退還的 Stake
+func (p *Paytable) Payout(handType HandType) int {
+	pay, ok := p.Pays[handType]
+	if !ok {
+		return 0
+	}
+	if p.Mode == PayTo {
+		return pay + p.Stake
+	}
+	return pay
+}
@@ -0,0 +1,113 @@
+package card
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPaytableMatchesGetOdds(t *testing.T) {
+	paytable := DefaultPaytable()
+	if err := paytable.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, handType := range AllHandTypes {
+		if paytable.Payout(handType) != handType.GetOdds() {
+			t.Errorf("%s: got %d, expected %d", handType.ToString(), paytable.Payout(handType), handType.GetOdds())
+		}
+	}
+}
+
+func TestDefaultPaytableMonotonic(t *testing.T) {
+	paytable := DefaultPaytable()
+	for i := 1; i < len(AllHandTypes); i++ {
+		lower, higher := AllHandTypes[i-1], AllHandTypes[i]
+		if paytable.Payout(higher) < paytable.Payout(lower) {
+			t.Errorf("%s pays %d, less than %s (%d)", higher.ToString(), paytable.Payout(higher), lower.ToString(), paytable.Payout(lower))
+		}
+	}
+
+	// 四張百搭加上 T 組成同花大順, 不能比四張百搭加上 9 的五條賠得少
+	royal, five := MustParseHand("2c 2d 2h 2s Ts"), MustParseHand("2c 2d 2h 2s 9s")
+	royalType, fiveType := DeucesWildRules.GetHandType(royal), DeucesWildRules.GetHandType(five)
+	if royalType != RoyalFlush || fiveType != FiveOfAKind {
+		t.Fatalf("expected royal flush and five of a kind, got %s and %s", royalType.ToString(), fiveType.ToString())
+	}
+	if paytable.Payout(royalType) < paytable.Payout(fiveType) {
+		t.Errorf("expected wild royal flush to pay at least five of a kind, got %d < %d", paytable.Payout(royalType), paytable.Payout(fiveType))
+	}
+}
+
+func TestParsePaytable(t *testing.T) {
+	jsonTable, err := ParsePaytableJSON([]byte(`{"version":"v2","mode":"to","pays":{"Pair":1,"two_pair":2,"RoyalFlush":800}}`))
+	if err != nil {
+		t.Fatalf("JSON error: %v", err)
+	}
+	yamlTable, err := ParsePaytableYAML([]byte("version: v2\nmode: to\npays:\n  Pair: 1\n  TwoPair: 2\n  royal flush: 800\n"))
+	if err != nil {
+		t.Fatalf("YAML error: %v", err)
+	}
+
+	for _, paytable := range []*Paytable{jsonTable, yamlTable} {
+		if paytable.Version != "v2" || paytable.Mode != PayTo {
+			t.Errorf("Unexpected header %q %q", paytable.Version, paytable.Mode)
+		}
+		// x to 1 會退還押注, 押注為開局花費而不是固定1點
+		staked := paytable.WithStake(10)
+		if staked.Payout(Pair) != 11 || staked.Payout(TwoPair) != 12 || staked.Payout(RoyalFlush) != 810 {
+			t.Errorf("Unexpected payouts %v", paytable.Pays)
+		}
+		if paytable.Stake != 0 || paytable.Payout(Pair) != 1 {
+			t.Errorf("Expected WithStake not to modify the original paytable")
+		}
+		if paytable.Payout(HighCard) != 0 || paytable.Payout(Flush) != 0 {
+			t.Errorf("Expected unlisted hand types to pay 0")
+		}
+	}
+
+	data, err := json.Marshal(DefaultPaytable())
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	roundTrip, err := ParsePaytableJSON(data)
+	if err != nil || roundTrip.Payout(FullHouse) != 50 || roundTrip.Mode != PayFor {
+		t.Errorf("Unexpected round trip %v, %v", roundTrip, err)
+	}
+}
+
+func TestParsePaytableErrors(t *testing.T) {
+	tests := []string{
+		`{"mode":"for","pays":{"Pair":1}}`,
+		`{"version":"v1","mode":"against","pays":{"Pair":1}}`,
+		`{"version":"v1","pays":{}}`,
+		`{"version":"v1","pays":{"Pair":-1}}`,
+		`{"version":"v1","pays":{"Trips":3}}`,
+	}
+	for _, input := range tests {
+		if _, err := ParsePaytableJSON([]byte(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+
+	paytable, err := ParsePaytableJSON([]byte(`{"version":"v1","pays":{"Pair":1}}`))
+	if err != nil || paytable.Mode != PayFor {
+		t.Errorf("Expected empty mode to default to for, got %v, %v", paytable, err)
+	}
+}
+
+func TestLoadPaytable(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "paytable.yml")
+	if err := os.WriteFile(yamlPath, []byte("version: ab-test-b\npays:\n  FullHouse: 45\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paytable, err := LoadPaytable(yamlPath)
+	if err != nil || paytable.Version != "ab-test-b" || paytable.Payout(FullHouse) != 45 {
+		t.Errorf("Unexpected paytable %v, %v", paytable, err)
+	}
+
+	if _, err := LoadPaytable(filepath.Join(dir, "paytable.txt")); err == nil {
+		t.Errorf("Expected error for missing file")
+	}
+}
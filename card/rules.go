@@ -0,0 +1,94 @@
+package card
+
+// Rules 牌組組成與牌型評估的玩法規則
+type Rules struct {
+	Deck        DeckSpec   // 牌組組成, 零值為標準52張
+	Jokers      int        // 牌組中加入的鬼牌張數, 鬼牌一律是百搭
+	WildNumbers []int      // 視為百搭的點數, 例如百搭2玩法為 []int{2}
+	HandTypes   []HandType // 啟用的牌型, nil 表示全部啟用; 停用的牌型會往下歸類到手牌實際符合的次大牌型
+}
+
+// ClassicHandTypes 沒有兩對、五條與同花大順的牌型設定
+var ClassicHandTypes = []HandType{HighCard, Pair, ThreeOfAKind, Straight, Flush, FullHouse, FourOfAKind, StraightFlush}
+
+var (
+	// StandardRules 標準52張牌, 沒有百搭
+	StandardRules = &Rules{}
+	// JokerPokerRules 加入1張鬼牌當百搭
+	JokerPokerRules = &Rules{Jokers: 1}
+	// DeucesWildRules 所有的2都是百搭
+	DeucesWildRules = &Rules{WildNumbers: []int{2}}
+)
+
+// IsWild 這張牌在此規則下是否為百搭
+func (r *Rules) IsWild(card *Card) bool {
+	if card.IsJoker() {
+		return true
+	}
+	for _, number := range r.WildNumbers {
+		if card.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnabled 此規則是否啟用某個牌型, 高牌一律啟用
+func (r *Rules) IsEnabled(handType HandType) bool {
+	if r.HandTypes == nil || handType == HighCard {
+		return true
+	}
+	for _, enabled := range r.HandTypes {
+		if enabled == handType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDeck 依規則建立一副新的牌, 依 Idx 由小到大排序
+func (r *Rules) NewDeck() []*Card {
+	deck := r.Deck.Build()
+	for i := 1; i <= r.Jokers; i++ {
+		deck = append(deck, NewJoker(i))
+	}
+	return deck
+}
+
+// Evaluate 依規則從傳入的牌中找出最大的5張組合, 百搭會替換成最有利的牌
+func (r *Rules) Evaluate(cards []*Card) HandRank {
+	naturals := make([]*Card, 0, len(cards))
+	var wilds []*Card
+	for _, card := range cards {
+		if r.IsWild(card) {
+			wilds = append(wilds, card)
+		} else {
+			naturals = append(naturals, card)
+		}
+	}
+	return evaluate(r, naturals, wilds)
+}
+
+// 依牌組點數產生的順子
+func (r *Rules) straights() []straightWindow {
+	if r.Deck.Numbers == nil {
+		return standardStraights
+	}
+	return r.Deck.straights()
+}
+
+// GetHandType 依規則取得最大的牌型, 標準點數、沒有百搭且查表結果是啟用的牌型時直接使用查表結果
+func (r *Rules) GetHandType(cards []*Card) HandType {
+	if !r.Deck.IsStandardRanks() {
+		return r.Evaluate(cards).Type
+	}
+	for _, card := range cards {
+		if r.IsWild(card) {
+			return r.Evaluate(cards).Type
+		}
+	}
+	if handType := GetHandType(cards); r.IsEnabled(handType) {
+		return handType
+	}
+	return r.Evaluate(cards).Type
+}
@@ -0,0 +1,170 @@
+package card
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRulesEvaluateWild(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    *Rules
+		hand     string
+		expected HandType
+		ranks    []int
+	}{
+		{"JokerMakesFiveOfAKind", JokerPokerRules, "As Ad Ah Ac JK", FiveOfAKind, []int{14}},
+		{"JokerFillsStraightFlush", JokerPokerRules, "9h Th Jh Kh JK", StraightFlush, []int{13}},
+		{"JokerPrefersHighStraight", JokerPokerRules, "Th Jd Qc Ks JK", Straight, []int{14}},
+		{"JokerMakesAceHighFlush", JokerPokerRules, "2s 5s 7s 9s JK", Flush, []int{14, 9, 7, 5, 2}},
+		{"JokerMakesFullHouse", JokerPokerRules, "Ks Kd 5c 5h JK", FullHouse, []int{13, 5}},
+		{"JokerMakesTrips", JokerPokerRules, "Ks Kd 9c 5h JK", ThreeOfAKind, []int{13, 9, 5}},
+		{"JokerPairsHighestCard", JokerPokerRules, "Ks 2d 9c 5h JK", Pair, []int{13, 9, 5, 2}},
+		{"DeucesMakeQuads", DeucesWildRules, "2s 2d 9c 9h Kc", FourOfAKind, []int{9, 13}},
+		{"DeucesMakeFive", DeucesWildRules, "2s 2d 2c 9h 9c", FiveOfAKind, []int{9}},
+		{"DeucesAllWildRoyal", DeucesWildRules, "2s 2d 2c 2h Kc", RoyalFlush, []int{14}},
+		{"DeucesAllWildFive", DeucesWildRules, "2s 2d 2c 2h 9c", FiveOfAKind, []int{9}},
+		{"DeucesFillWheel", DeucesWildRules, "As 2d 3c 4h 5c", Straight, []int{5}},
+		{"DeucesFillSixHigh", DeucesWildRules, "6s 2d 3c 4h 5c", Straight, []int{7}},
+		{"NaturalTwoWithoutWild", StandardRules, "2s 2d 9c 9h Kc", TwoPair, []int{9, 2, 13}},
+	}
+
+	for _, tt := range tests {
+		cards := MustParseHand(tt.hand)
+		rank := tt.rules.Evaluate(cards)
+		if rank.Type != tt.expected {
+			t.Errorf("%s: got %v, expected %v", tt.name, rank.Type.ToString(), tt.expected.ToString())
+		}
+		if !reflect.DeepEqual(rank.Ranks, tt.ranks) {
+			t.Errorf("%s: got ranks %v, expected %v", tt.name, rank.Ranks, tt.ranks)
+		}
+		if len(rank.Cards) != 5 {
+			t.Errorf("%s: expected 5 cards, got %d", tt.name, len(rank.Cards))
+		}
+		if tt.rules.GetHandType(cards) != tt.expected {
+			t.Errorf("%s: GetHandType got %v", tt.name, tt.rules.GetHandType(cards).ToString())
+		}
+	}
+}
+
+func TestRulesEvaluateWildSevenCards(t *testing.T) {
+	// 百搭當 A 組成同花比用原本的小牌更大
+	rank := JokerPokerRules.Evaluate(MustParseHand("3s 5s 7s 8s 9s Kd JK"))
+	if rank.Type != StraightFlush || rank.Ranks[0] != 9 {
+		t.Errorf("Expected 9 high straight flush, got %v", rank.ToString())
+	}
+	rank = JokerPokerRules.Evaluate(MustParseHand("3s 5s 7s 9s Js Kd JK"))
+	if rank.Type != Flush || !reflect.DeepEqual(rank.Ranks, []int{14, 11, 9, 7, 5}) {
+		t.Errorf("Expected ace high flush, got %v", rank.ToString())
+	}
+}
+
+func TestRulesNewDeck(t *testing.T) {
+	deck := JokerPokerRules.NewDeck()
+	if len(deck) != 53 {
+		t.Fatalf("Expected 53 cards, got %d", len(deck))
+	}
+	joker := deck[52]
+	if !joker.IsJoker() || joker.Idx != 53 || !JokerPokerRules.IsWild(joker) {
+		t.Errorf("Expected last card to be a wild joker, got %+v", joker)
+	}
+	if NewCardSet(deck...).Count() != 53 {
+		t.Errorf("Expected jokers to fit in a CardSet")
+	}
+	parsed, err := ParseCard(joker.Notation())
+	if err != nil || parsed.Idx != joker.Idx {
+		t.Errorf("Expected joker notation round trip, got %v, %v", parsed, err)
+	}
+	if len(StandardRules.NewDeck()) != 52 {
+		t.Errorf("Expected standard deck to have 52 cards")
+	}
+}
+
+func TestRulesHandTypes(t *testing.T) {
+	classic := &Rules{HandTypes: ClassicHandTypes}
+	tests := []struct {
+		name     string
+		rules    *Rules
+		hand     string
+		expected HandType
+	}{
+		{"RoyalFlush", StandardRules, "Ts Js Qs Ks As", RoyalFlush},
+		{"RoyalCollapsesToStraightFlush", classic, "Ts Js Qs Ks As", StraightFlush},
+		{"TwoPairCollapsesToPair", classic, "2s 2d 9c 9h Kc", Pair},
+		{"FiveCollapsesToQuads", &Rules{WildNumbers: []int{2}, HandTypes: ClassicHandTypes}, "2s 2d 2c 9h 9c", FourOfAKind},
+		{"StraightDisabled", &Rules{HandTypes: []HandType{Pair, Flush}}, "9s Td Jc Qh Kc", HighCard},
+		{"FlushDisabledFallsToPair", &Rules{HandTypes: []HandType{Pair}}, "2s 2d 5d 7d 9d Jd", Pair},
+		{"OnlyRoyalEnabled", &Rules{HandTypes: []HandType{RoyalFlush}}, "9s Ts Js Qs Ks", HighCard},
+	}
+
+	for _, tt := range tests {
+		cards := MustParseHand(tt.hand)
+		if got := tt.rules.Evaluate(cards).Type; got != tt.expected {
+			t.Errorf("%s: Evaluate got %v, expected %v", tt.name, got.ToString(), tt.expected.ToString())
+		}
+		if got := tt.rules.GetHandType(cards); got != tt.expected {
+			t.Errorf("%s: GetHandType got %v, expected %v", tt.name, got.ToString(), tt.expected.ToString())
+		}
+	}
+
+	// 停用兩對時, 兩對以對子加踢腳比大小
+	kingsAndFives := classic.Evaluate(MustParseHand("Ks Kd 5c 5h 2c"))
+	acesPair := classic.Evaluate(MustParseHand("As Ad 9c 7h 2c"))
+	if !kingsAndFives.Less(acesPair) {
+		t.Errorf("Expected a pair of aces to beat kings and fives when two pair is disabled")
+	}
+}
+
+func TestGetIndicesNewHandTypes(t *testing.T) {
+	hand := MustParseHand("9c Kh 9d 2s Ks")
+	if !IsTwoPair(hand) || !IsHandType(hand, TwoPair) {
+		t.Errorf("Expected two pair")
+	}
+	if indices := GetTwoPairIndices(hand); !reflect.DeepEqual(indices, []int{0, 2, 1, 4}) {
+		t.Errorf("Unexpected two pair indices %v", indices)
+	}
+
+	royal := MustParseHand("Ah 2c Kh Qh Jh Th")
+	if !IsRoyalFlush(royal) || !IsHandType(royal, RoyalFlush) {
+		t.Errorf("Expected royal flush")
+	}
+	if indices := GetRoyalFlushIndices(royal); !reflect.DeepEqual(indices, []int{5, 4, 3, 2, 0}) {
+		t.Errorf("Unexpected royal flush indices %v", indices)
+	}
+	if IsRoyalFlush(MustParseHand("Ah Kh Qh Jh 9h")) {
+		t.Errorf("Expected no royal flush")
+	}
+}
+
+func TestIsHandTypeNaturalOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     *Rules
+		hand      string
+		natural   HandType // IsHandType 成立的最大牌型
+		evaluated HandType // Rules.Evaluate 的牌型
+	}{
+		{"JokerFive", JokerPokerRules, "As Ad Ah Ac JK", FourOfAKind, FiveOfAKind},
+		{"JokerPair", JokerPokerRules, "Ac Kd 9h 5c JK", HighCard, Pair},
+		{"DeucesFive", DeucesWildRules, "2c 2d 2h 2s 9s", FourOfAKind, FiveOfAKind},
+		{"DeucesRoyal", DeucesWildRules, "2c 2d 2h Ks Ts", ThreeOfAKind, RoyalFlush},
+		{"NoWild", StandardRules, "9c 9d 9h Ks Kd", FullHouse, FullHouse},
+		{"TwoDecks", &Rules{Deck: DeckSpec{Copies: 2}}, "As As Ad Ah Ac", FiveOfAKind, FiveOfAKind},
+	}
+
+	for _, tt := range tests {
+		cards := MustParseHand(tt.hand)
+		if got := tt.rules.Evaluate(cards).Type; got != tt.evaluated {
+			t.Errorf("%s: Evaluate got %s, expected %s", tt.name, got.ToString(), tt.evaluated.ToString())
+		}
+		natural := HighCard
+		for _, handType := range AllHandTypes {
+			if IsHandType(cards, handType) {
+				natural = handType
+			}
+		}
+		if natural != tt.natural {
+			t.Errorf("%s: IsHandType got %s, expected %s", tt.name, natural.ToString(), tt.natural.ToString())
+		}
+	}
+}
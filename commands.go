@@ -0,0 +1,465 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math-discard-card/analysis"
+	"math-discard-card/card"
+	"math-discard-card/i18n"
+	"math-discard-card/simulation"
+	"math-discard-card/utility"
+	"math/big"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 各指令共用的參數
+type options struct {
+	locale       string
+	rulesName    string
+	paytablePath string
+	json         bool
+	gameCost     int
+	discardCost  int
+	discardAdd   int
+	maxRounds    int
+	handSize     int
+	workers      int
+	progress     bool
+}
+
+// 內建的玩法規則
+var rulesByName = map[string]*card.Rules{
+	"standard": card.StandardRules,
+	"joker":    card.JokerPokerRules,
+	"deuces":   card.DeucesWildRules,
+}
+
+func (o *options) register(fs *flag.FlagSet, withGame bool) {
+	fs.StringVar(&o.locale, "lang", string(i18n.DefaultLocale), "輸出語系(zh-TW、en)")
+	fs.StringVar(&o.rulesName, "rules", "standard", "玩法規則(standard、joker、deuces)")
+	fs.StringVar(&o.paytablePath, "paytable", "", "賠率表設定檔(.json、.yaml), 空白為預設賠率表")
+	fs.BoolVar(&o.json, "json", false, "以 JSON 輸出")
+	fs.IntVar(&o.workers, "workers", runtime.NumCPU(), "平行計算的 worker 數量")
+	fs.BoolVar(&o.progress, "progress", o.progress, "在 stderr 顯示計算進度")
+	fs.IntVar(&o.handSize, "hand-size", 5, "手牌張數, 超過5張時以最大的5張組合結算")
+	if withGame {
+		fs.IntVar(&o.gameCost, "game-cost", 10, "開局花費")
+		fs.IntVar(&o.discardCost, "discard-cost", 1, "第一次換牌的花費")
+		fs.IntVar(&o.discardAdd, "discard-add", 1, "每多換一次增加的花費")
+		fs.IntVar(&o.maxRounds, "max-rounds", 1, "一局最多換幾次牌, 0 表示不限")
+	}
+}
+
+// 指令列輸出錯誤時使用的語系, 解析 -lang 後更新
+var cliLocale = i18n.DefaultLocale
+
+func (o *options) loadLocale() (i18n.Locale, error) {
+	locale, err := i18n.ParseLocale(o.locale)
+	if err != nil {
+		return "", err
+	}
+	cliLocale = locale
+	return locale, nil
+}
+
+func (o *options) loadRules() (*card.Rules, error) {
+	rules, ok := rulesByName[o.rulesName]
+	if !ok {
+		return nil, i18n.Errorf("error.rules", o.rulesName)
+	}
+	return rules, nil
+}
+
+func (o *options) loadPaytable() (*card.Paytable, error) {
+	if o.paytablePath == "" {
+		return card.DefaultPaytable(), nil
+	}
+	return card.LoadPaytable(o.paytablePath)
+}
+
+func (o *options) gameConfig() analysis.GameConfig {
+	return analysis.GameConfig{
+		GameCost:           o.gameCost,
+		DefaultDiscardCost: o.discardCost,
+		DiscardAddCost:     o.discardAdd,
+		HandSize:           o.handSize,
+		MaxRounds:          o.maxRounds,
+	}
+}
+
+// 建立分析器並取得輸出語系
+func (o *options) analyzer() (*analysis.Analyzer, i18n.Locale, error) {
+	locale, err := o.loadLocale()
+	if err != nil {
+		return nil, "", err
+	}
+	rules, err := o.loadRules()
+	if err != nil {
+		return nil, "", err
+	}
+	paytable, err := o.loadPaytable()
+	if err != nil {
+		return nil, "", err
+	}
+	// PayTo 賠率表退還的押注為開局花費
+	analyzer := analysis.NewAnalyzer(rules, paytable.WithStake(o.gameCost))
+	analyzer.Engine = &analysis.Engine{Workers: o.workers}
+	if o.progress {
+		analyzer.Engine.Progress = func(done, total int64) {
+			fmt.Fprintf(os.Stderr, "\r%s", i18n.T(locale, "cli.progress", done, total, float64(done)*100/float64(total)))
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+	return analyzer, locale, nil
+}
+
+// 解析 -hand 的手牌, 沒有指定或張數與 -hand-size 不同時顯示用法並回傳錯誤
+func (o *options) parseHand(fs *flag.FlagSet, str string) ([]*card.Card, error) {
+	hand, err := card.ParseHand(str)
+	if err != nil {
+		return nil, err
+	}
+	if len(hand) == 0 || len(hand) != o.handSize {
+		fs.Usage()
+		return nil, i18n.Errorf("error.hand_flag", o.handSize, len(hand))
+	}
+	return hand, nil
+}
+
+// 解析以逗號分隔的手牌位置, 例如 "0,2"
+func parseIdxs(str string) ([]int, error) {
+	idxs := []int{}
+	for _, idxStr := range strings.Split(str, ",") {
+		idxStr = strings.TrimSpace(idxStr)
+		if idxStr == "" {
+			continue
+		}
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil {
+			return nil, i18n.Errorf("error.index", idxStr)
+		}
+		idxs = append(idxs, idx)
+	}
+	return idxs, nil
+}
+
+func parseCards(str string) ([]*card.Card, error) {
+	if strings.TrimSpace(str) == "" {
+		return nil, nil
+	}
+	return card.ParseHand(str)
+}
+
+// 手牌的文字表示法, 例如 "As Kd"
+func notation(cards []*card.Card) string {
+	strs := make([]string, len(cards))
+	for i, c := range cards {
+		strs[i] = c.Notation()
+	}
+	return strings.Join(strs, " ")
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// odds: 換牌後的牌型分布
+func runOdds(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("odds", flag.ExitOnError)
+	o.register(fs, false)
+	handStr := fs.String("hand", "", "手牌, 例如 \"5c 6c Qh 4d Ts\"")
+	discardStr := fs.String("discard", "", "要換掉的手牌位置, 例如 \"2,3,4\"")
+	deadStr := fs.String("dead", "", "已知不在牌堆中的牌")
+	decimals := fs.Int("decimals", 10, "機率的小數位數, 以最大餘數法捨入讓加總為1")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	analyzer, locale, err := o.analyzer()
+	if err != nil {
+		return err
+	}
+	hand, err := o.parseHand(fs, *handStr)
+	if err != nil {
+		return err
+	}
+	discard, err := parseIdxs(*discardStr)
+	if err != nil {
+		return err
+	}
+	dead, err := parseCards(*deadStr)
+	if err != nil {
+		return err
+	}
+
+	outcomes, err := analyzer.DiscardOutcomes(hand, discard, dead)
+	if err != nil {
+		return err
+	}
+	exact := outcomes.ExactProbabilities()
+	rounded := outcomes.RoundedProbabilities(*decimals)
+	if o.json {
+		probabilities := make(map[card.HandType]string, len(rounded))
+		for handType, probability := range rounded {
+			probabilities[handType] = utility.FormatRat(probability, *decimals)
+		}
+		return printJSON(struct {
+			*analysis.Outcomes
+			Exact         map[card.HandType]*big.Rat `json:"exact"`
+			Probabilities map[card.HandType]string   `json:"probabilities"`
+		}{outcomes, exact, probabilities})
+	}
+	fmt.Println(i18n.T(locale, "odds.header", notation(hand), discard, outcomes.Total))
+	for _, handType := range card.AllHandTypes {
+		fmt.Println(i18n.T(locale, "odds.row", handType.Name(locale), outcomes.Counts[handType], exact[handType].RatString(),
+			utility.FormatRat(rounded[handType], *decimals), utility.FormatPercent(rounded[handType], max(*decimals-2, 0))))
+	}
+	return nil
+}
+
+// ev: 所有換牌選擇的期望值
+func runEV(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("ev", flag.ExitOnError)
+	o.register(fs, true)
+	handStr := fs.String("hand", "", "手牌, 例如 \"Ah Kh Qh Jh 2c\"")
+	deadStr := fs.String("dead", "", "已知不在牌堆中的牌")
+	discardCount := fs.Int("discard-count", 0, "這局已經換過幾次牌")
+	top := fs.Int("top", 10, "只列出前幾名, 0 表示全部")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	analyzer, locale, err := o.analyzer()
+	if err != nil {
+		return err
+	}
+	hand, err := o.parseHand(fs, *handStr)
+	if err != nil {
+		return err
+	}
+	dead, err := parseCards(*deadStr)
+	if err != nil {
+		return err
+	}
+
+	cost := o.gameConfig().DiscardCost().At(*discardCount)
+	results, err := analyzer.SolveHold(hand, cost, dead)
+	if err != nil {
+		return err
+	}
+	if *top > 0 && *top < len(results) {
+		results = results[:*top]
+	}
+	// 可以換不只一次時另外計算多次換牌的最佳選擇
+	var decision *analysis.Decision
+	if o.maxRounds != 1 {
+		planner := analysis.NewPlanner(analyzer, o.gameConfig().DiscardCost(), o.maxRounds)
+		plan, err := planner.Decide(analysis.State{Hand: hand, Dead: dead, DiscardCount: *discardCount})
+		if err != nil {
+			return err
+		}
+		decision = &plan
+	}
+
+	if o.json {
+		return printJSON(struct {
+			Results  []analysis.HoldResult `json:"results"`
+			Decision *analysis.Decision    `json:"decision,omitempty"`
+		}{results, decision})
+	}
+	fmt.Println(i18n.T(locale, "ev.header", cost))
+	for _, result := range results {
+		mark := "  "
+		if result.Best {
+			mark = "* "
+		}
+		fmt.Println(i18n.T(locale, "ev.row", mark, result.Discard, utility.FormatRat(result.ExactEV, 6), utility.FormatRat(result.ExactNetEV, 6)))
+	}
+	if decision != nil {
+		action := i18n.T(locale, "ev.settle")
+		if !decision.Settle {
+			action = i18n.T(locale, "ev.draw", decision.Discard)
+		}
+		fmt.Println(i18n.T(locale, "ev.plan", o.maxRounds, action, utility.FormatRat(decision.ExactValue, 6)))
+	}
+	return nil
+}
+
+// rtp: 整個遊戲的理論回報
+func runRTP(args []string) error {
+	// 完整牌組以 optimal 計算要很久, 預設用 never 並顯示進度
+	o := options{progress: true}
+	fs := flag.NewFlagSet("rtp", flag.ExitOnError)
+	o.register(fs, true)
+	strategyName := fs.String("strategy", "never", "策略("+strings.Join(analysis.StrategyNames, "、")+"), optimal 在完整牌組上要計算很久")
+	decimals := fs.Int("decimals", 10, "機率的小數位數, 以最大餘數法捨入讓加總為1")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	analyzer, locale, err := o.analyzer()
+	if err != nil {
+		return err
+	}
+	strategy, err := analyzer.NewStrategy(*strategyName, o.gameConfig())
+	if err != nil {
+		return err
+	}
+
+	report, err := analyzer.RTP(o.gameConfig(), strategy)
+	if err != nil {
+		return err
+	}
+	if o.json {
+		return printJSON(report)
+	}
+	fmt.Println(i18n.T(locale, "rtp.strategy", report.Strategy, report.StartingHands))
+	fmt.Println(i18n.T(locale, "rtp.payout", utility.FormatRat(report.ExactPayout, 6), utility.FormatRat(report.ExactCost, 6)))
+	fmt.Println(i18n.T(locale, "rtp.rtp", formatPercent(report.ExactRTP, 6), formatPercent(report.ExactHouseEdge, 6), report.StdDev))
+	fmt.Println(i18n.T(locale, "rtp.hit_header"))
+	hits := roundHitFrequency(report.ExactHitFrequency, *decimals)
+	for _, handType := range card.AllHandTypes {
+		fmt.Println(i18n.T(locale, "rtp.hit", handType.Name(locale), utility.FormatRat(hits[handType], *decimals)))
+	}
+	return nil
+}
+
+// simulate: 蒙地卡羅模擬
+func runSimulate(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	o.register(fs, true)
+	strategyName := fs.String("strategy", "never", "策略("+strings.Join(analysis.StrategyNames, "、")+")")
+	rounds := fs.Int("rounds", 100000, "模擬局數")
+	seed := fs.Int64("seed", 1, "亂數種子")
+	rng := fs.String("rng", "pcg", "洗牌用的亂數演算法("+strings.Join(simulation.RNGNames, "、")+"), 模擬要能以 -seed 重現, 不支援 crypto")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	analyzer, locale, err := o.analyzer()
+	if err != nil {
+		return err
+	}
+	strategy, err := analyzer.NewStrategy(*strategyName, o.gameConfig())
+	if err != nil {
+		return err
+	}
+
+	report, err := simulation.Run(simulation.Config{
+		Rounds:   *rounds,
+		Seed:     *seed,
+		RNG:      *rng,
+		Game:     o.gameConfig(),
+		Rules:    analyzer.Rules,
+		Paytable: analyzer.Paytable,
+		Strategy: strategy,
+		Engine:   analyzer.Engine,
+	})
+	if err != nil {
+		return err
+	}
+	if o.json {
+		return printJSON(report)
+	}
+	fmt.Println(i18n.T(locale, "simulate.header", report.Strategy, report.Rounds, report.Seed))
+	fmt.Println(i18n.T(locale, "simulate.rtp", formatPercent(report.ExactRTP, 6), report.RTPCI95[0]*100, report.RTPCI95[1]*100))
+	metrics := []struct {
+		key     string
+		summary simulation.Summary
+	}{
+		{"simulate.net", report.Net},
+		{"simulate.payout", report.Payout},
+		{"simulate.cost", report.Cost},
+		{"simulate.discards", report.Discards},
+	}
+	for _, metric := range metrics {
+		percentiles := []float64{}
+		for _, p := range simulation.Percentiles {
+			percentiles = append(percentiles, metric.summary.Percentiles[p])
+		}
+		fmt.Println(i18n.T(locale, "simulate.metric", i18n.T(locale, metric.key), metric.summary.Mean, metric.summary.StdDev,
+			metric.summary.CI95[0], metric.summary.CI95[1], percentiles))
+	}
+	fmt.Println(i18n.T(locale, "simulate.hit_header"))
+	hits := roundHitFrequency(report.ExactHitFrequency, 6)
+	for _, handType := range card.AllHandTypes {
+		ci := report.HitCI95[handType]
+		fmt.Println(i18n.T(locale, "simulate.hit", handType.Name(locale), utility.FormatRat(hits[handType], 6), ci[0], ci[1]))
+	}
+	return nil
+}
+
+// chart: 所有起手牌的最佳留牌策略表
+func runChart(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("chart", flag.ExitOnError)
+	o.register(fs, true)
+	format := fs.String("format", "csv", "輸出格式(csv、json)")
+	output := fs.String("o", "", "輸出檔案, 空白為標準輸出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// 先載入語系, 參數錯誤也以指定的語系顯示
+	if _, err := o.loadLocale(); err != nil {
+		return err
+	}
+	var write func(w io.Writer, entries []analysis.ChartEntry) error
+	switch *format {
+	case "csv":
+		write = analysis.WriteChartCSV
+	case "json":
+		write = analysis.WriteChartJSON
+	default:
+		return i18n.Errorf("error.format", *format)
+	}
+	analyzer, _, err := o.analyzer()
+	if err != nil {
+		return err
+	}
+	entries, err := analyzer.StrategyChart(o.gameConfig())
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return write(os.Stdout, entries)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := write(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// 依 card.AllHandTypes 的順序以最大餘數法捨入各牌型機率, 顯示的數字加總為1, 與 odds 相同
+func roundHitFrequency(exact map[card.HandType]*big.Rat, decimals int) map[card.HandType]*big.Rat {
+	values := make([]*big.Rat, len(card.AllHandTypes))
+	for i, handType := range card.AllHandTypes {
+		values[i] = exact[handType]
+	}
+	rounded := utility.RoundRats(values, decimals)
+	hits := make(map[card.HandType]*big.Rat, len(rounded))
+	for i, handType := range card.AllHandTypes {
+		hits[handType] = rounded[i]
+	}
+	return hits
+}
+
+// 精確比例轉成百分比字串, 沒有值(例如花費為0)時顯示 "-"
+func formatPercent(r *big.Rat, decimals int) string {
+	if r == nil {
+		return "-"
+	}
+	return utility.FormatPercent(r, decimals)
+}
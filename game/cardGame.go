@@ -2,64 +2,173 @@ package game
 
 import (
 	"fmt"
+	"io"
+	"math-discard-card/analysis"
 	"math-discard-card/card"
-	"math/rand"
+	"math-discard-card/i18n"
+	"math-discard-card/utility"
+	"os"
 )
 
-var MyGame *CardGame
-
 type CardGame struct {
 	Deck               []*card.Card
 	HandCards          []*card.Card
-	DeckAvailableDic   map[int]bool
 	GameCost           int
 	DefaultDiscardCost int
 	DiscardAddCost     int
 	CurDiscardCount    int
+	HandSize           int            // 手牌張數, 0 為5張; 超過5張時以最大的5張組合結算
+	ChooseHand         bool           // 手牌超過5張時由玩家以 SelectCards 選擇結算的5張, 沒選時仍以最大的組合結算
+	Selected           []int          // 玩家選擇結算的手牌位置, 開局或換牌後清空
+	Player             *Player        // 這個牌局的玩家, 開局、換牌、結算前必須設定
+	Rules              *card.Rules    // 牌組組成與百搭規則
+	Paytable           *card.Paytable // 結算用的賠率表
+	RNG                utility.RNG    // 洗牌用的亂數, nil 時使用全域亂數; 先以 utility.WrapRNG 包裝可以避免每次洗牌配置
+	Output             io.Writer      // 遊戲訊息的輸出, nil 時為標準輸出, 模擬時可設為 io.Discard
+
+	deckPile cardPile // 與 Deck 同步的集合
+	handPile cardPile // 與 HandCards 同步的集合
+}
+
+// 以 CardSet 記錄的一疊牌, 多副牌時同一牌面有多張, 張數歸零才從集合中移除
+type cardPile struct {
+	set    card.CardSet
+	counts [64]uint8
+}
+
+func newCardPile(cards []*card.Card) cardPile {
+	var p cardPile
+	for _, c := range cards {
+		p.add(c)
+	}
+	return p
+}
+
+func (p *cardPile) add(c *card.Card) {
+	p.counts[c.Idx]++
+	p.set.Add(c)
+}
+
+func (p *cardPile) remove(c *card.Card) {
+	if p.counts[c.Idx] == 0 {
+		return
+	}
+	p.counts[c.Idx]--
+	if p.counts[c.Idx] == 0 {
+		p.set.Remove(c)
+	}
+}
+
+func (g *CardGame) handSize() int {
+	if g.HandSize <= 0 {
+		return 5
+	}
+	return g.HandSize
 }
 
-func InitCardGame(gameCost, defaultDiscardCost, discardAddCost int) {
-	MyGame = &CardGame{
-		GameCost:           gameCost,
-		DefaultDiscardCost: defaultDiscardCost,
-		DiscardAddCost:     discardAddCost,
+// 這個牌局的玩家, 沒有設定時回傳錯誤
+func (g *CardGame) player() (*Player, error) {
+	if g.Player == nil {
+		return nil, i18n.Errorf("error.no_player")
 	}
-	MyGame.initDeck()
+	return g.Player, nil
 }
 
 func (g *CardGame) curDiscardCost() int {
 	return g.DefaultDiscardCost + (g.CurDiscardCount * g.DiscardAddCost)
 }
 
+// SetSeed 以固定種子的 PCG 亂數洗牌, 相同種子與操作會得到相同的牌局
+func (g *CardGame) SetSeed(seed int64) {
+	g.RNG = utility.WrapRNG(utility.NewPCG(seed))
+}
+
+func (g *CardGame) shuffle() {
+	utility.Shuffle(g.RNG, len(g.Deck), func(i, j int) {
+		g.Deck[i], g.Deck[j] = g.Deck[j], g.Deck[i]
+	})
+}
+
+func (g *CardGame) println(args ...any) {
+	output := g.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	fmt.Fprintln(output, args...)
+}
+
 func (g *CardGame) initDeck() {
-	g.Deck = []*card.Card{}
-	g.DeckAvailableDic = make(map[int]bool)
-	for suit := 0; suit < 4; suit++ {
-		for number := 1; number <= 13; number++ {
-			card := card.NewCard(card.SuitType(suit), number)
-			g.Deck = append(g.Deck, card)
-			g.DeckAvailableDic[card.Idx] = true
+	g.Deck = g.Rules.NewDeck()
+	g.deckPile = newCardPile(g.Deck)
+	g.clearHand()
+}
+
+func (g *CardGame) clearHand() {
+	g.HandCards = []*card.Card{}
+	g.handPile = cardPile{}
+}
+
+// SetHand 直接指定手牌, 會從牌堆中移除這些牌, 用來重現特定牌局
+//
+// 原本的手牌先放回牌堆; 牌堆中沒有的牌(牌組中沒有這張、或張數不夠)回傳錯誤, 手牌與牌堆不變
+func (g *CardGame) SetHand(cards []*card.Card) error {
+	available := g.deckPile.counts
+	for _, c := range g.HandCards {
+		available[c.Idx]++
+	}
+	for _, c := range cards {
+		if available[c.Idx] == 0 {
+			return i18n.Errorf("error.set_hand", c.ToString())
 		}
+		available[c.Idx]--
 	}
-}
 
-func (g *CardGame) resetDeckAvailableDic() {
-	for key := range g.DeckAvailableDic {
-		g.DeckAvailableDic[key] = true
+	for _, c := range g.HandCards {
+		g.Deck = append(g.Deck, c)
+		g.deckPile.add(c)
+	}
+	g.clearHand()
+	for _, c := range cards {
+		for i, d := range g.Deck {
+			if d.Idx == c.Idx {
+				g.Deck = append(g.Deck[:i], g.Deck[i+1:]...)
+				g.deckPile.remove(d)
+				break
+			}
+		}
+		g.HandCards = append(g.HandCards, c)
+		g.handPile.add(c)
 	}
+	g.Selected = nil
+	return nil
 }
 
-func (g *CardGame) NewGame(handIdxs ...int) {
-	rand.Shuffle(len(g.Deck), func(i, j int) {
-		g.Deck[i], g.Deck[j] = g.Deck[j], g.Deck[i]
-	})
+// RemainingSet 牌堆中還沒被抽出的牌, 多副牌時同一牌面只要還有任何一張就會包含在內
+//
+// 集合隨抽牌、換牌同步更新, 不會重新建立; 直接修改 Deck 後不會反映在集合中
+func (g *CardGame) RemainingSet() card.CardSet {
+	return g.deckPile.set
+}
+
+// HandSet 目前的手牌, 與 RemainingSet 相同隨抽牌、換牌同步更新
+func (g *CardGame) HandSet() card.CardSet {
+	return g.handPile.set
+}
+
+// NewGame 洗牌並發新的手牌, 扣除開局花費; handIdxs 指定要先發的牌
+func (g *CardGame) NewGame(handIdxs ...int) error {
+	player, err := g.player()
+	if err != nil {
+		return err
+	}
+	g.initDeck()
+	g.shuffle()
 	g.CurDiscardCount = 0
-	g.resetDeckAvailableDic()
+	g.Selected = nil
 	if len(handIdxs) == 0 {
 		g.drawInitialHand()
 	} else {
-		g.HandCards = []*card.Card{}
-		for i := 0; i < 5; i++ {
+		for i := 0; i < g.handSize(); i++ {
 			if i < len(handIdxs) {
 				g.drawCard(handIdxs[i])
 			} else {
@@ -67,26 +176,23 @@ func (g *CardGame) NewGame(handIdxs ...int) {
 			}
 		}
 	}
-	MyPlayer.AddPt(-g.GameCost)
-	log := fmt.Sprintf("新的一局遊戲 花費%v點遊玩 玩家點數: %v", g.GameCost, MyPlayer.Pt)
-	println(log)
+	player.AddPt(-g.GameCost)
+	g.println(g.msg("game.new", g.GameCost, player.Pt))
 	g.ShowCards()
+	return nil
 }
 
 func (g *CardGame) firstDrawInitialHand() {
-	g.HandCards = []*card.Card{}
+	g.clearHand()
 	// 四條
-	g.drawCard(1)
-	g.drawCard(14)
-	g.drawCard(27)
-	g.drawCard(40)
-	g.drawCard(15)
-
+	for _, c := range card.MustParseHand("Ac Ad Ah As 2d") {
+		g.drawCard(c.Idx)
+	}
 }
 
 func (g *CardGame) drawInitialHand() {
-	g.HandCards = []*card.Card{}
-	for i := 0; i < 7; i++ {
+	g.clearHand()
+	for i := 0; i < g.handSize(); i++ {
 		g.drawCard(0)
 	}
 }
@@ -96,74 +202,232 @@ func (g *CardGame) drawCard(idx int) *card.Card {
 		for i, card := range g.Deck {
 			if card.Idx == idx {
 				g.HandCards = append(g.HandCards, card)
-				g.DeckAvailableDic[card.Idx] = false
 				g.Deck = append(g.Deck[:i], g.Deck[i+1:]...)
+				g.handPile.add(card)
+				g.deckPile.remove(card)
 				return card
 			}
 		}
-		fmt.Printf("牌池無此idx的牌: %d\n", idx)
+		g.println(g.msg("game.no_card_idx", idx))
 		return nil
 	} else {
 		if len(g.Deck) > 0 {
 			card := g.Deck[0]
 			g.HandCards = append(g.HandCards, card)
-			g.DeckAvailableDic[card.Idx] = false
 			g.Deck = g.Deck[1:]
+			g.handPile.add(card)
+			g.deckPile.remove(card)
 			return card
 		}
 		return nil
 	}
 }
 
-func (g *CardGame) Settlement() {
+// SetPaytable 替換結算用的賠率表, PayTo 退還的押注為 GameCost
+func (g *CardGame) SetPaytable(paytable *card.Paytable) error {
+	if err := paytable.Validate(); err != nil {
+		return err
+	}
+	g.Paytable = paytable.WithStake(g.GameCost)
+	return nil
+}
+
+// Settlement 依目前的牌型結算, 點數加給玩家
+func (g *CardGame) Settlement() error {
+	player, err := g.player()
+	if err != nil {
+		return err
+	}
 	handType := g.GetHandType()
-	gainPT := handType.GetOdds()
-	MyPlayer.AddPt(gainPT)
-	log := fmt.Sprintf("結算牌型: %v  獲得點數: %v   玩家點數: %v", handType.ToString(), gainPT, MyPlayer.Pt)
-	println(log)
+	gainPT := g.Paytable.Payout(handType)
+	player.AddPt(gainPT)
+	g.println(g.msg("game.settlement", handType.Name(g.locale()), gainPT, player.Pt))
+	return nil
 }
 
-func (g *CardGame) DiscardCard(handIdxs ...int) {
+// DiscardCard 花點數換掉 handIdxs 位置的牌; 參數不對或點數不夠時只顯示訊息, 不換牌
+func (g *CardGame) DiscardCard(handIdxs ...int) error {
+	player, err := g.player()
+	if err != nil {
+		return err
+	}
 	if len(handIdxs) == 0 {
-		fmt.Println("傳入參數錯誤")
-		return
+		g.println(g.msg("game.invalid_args"))
+		return nil
 	}
-	if MyPlayer.Pt < g.curDiscardCost() {
-		fmt.Println("點數不夠")
-		return
+	if player.Pt < g.curDiscardCost() {
+		g.println(g.msg("game.not_enough_pt"))
+		return nil
 	}
-	MyPlayer.AddPt(-g.curDiscardCost())
-	costStr := fmt.Sprintf("重抽花費點數%v  玩家點數: %v", g.curDiscardCost(), MyPlayer.Pt)
-	fmt.Println(costStr)
+	player.AddPt(-g.curDiscardCost())
+	g.println(g.msg("game.discard_cost", g.curDiscardCost(), player.Pt))
 
 	newCards := []*card.Card{}
 	for _, handIdx := range handIdxs {
 		if handIdx >= 0 && handIdx < len(g.HandCards) {
 			if len(g.Deck) > 0 {
-				log := fmt.Sprintf("丟棄: %v", g.HandCards[handIdx].ToString())
+				log := g.msg("game.discard", g.HandCards[handIdx].ToString())
 				newCard := g.Deck[0]
 				g.Deck = g.Deck[1:]
 				newCards = append(newCards, newCard)
+				g.handPile.remove(g.HandCards[handIdx])
+				g.handPile.add(newCard)
+				g.deckPile.remove(newCard)
 				g.HandCards[handIdx] = newCard
-				g.DeckAvailableDic[newCard.Idx] = false
-				log += fmt.Sprintf("  抽到: %v", newCard.ToString())
-				fmt.Println(log)
+				log += g.msg("game.draw", newCard.ToString())
+				g.println(log)
 			}
 		}
 	}
 
 	g.CurDiscardCount++
+	g.Selected = nil
+	return nil
+}
+
+// SelectCards 選擇結算的5張手牌, 只有 ChooseHand 開啟且手牌超過5張時可以使用
+func (g *CardGame) SelectCards(handIdxs ...int) error {
+	if !g.ChooseHand || len(g.HandCards) <= 5 {
+		return i18n.Errorf("error.select_disabled")
+	}
+	if len(handIdxs) != 5 {
+		return i18n.Errorf("error.select_count", len(handIdxs))
+	}
+	seen := make(map[int]bool)
+	for _, handIdx := range handIdxs {
+		if handIdx < 0 || handIdx >= len(g.HandCards) || seen[handIdx] {
+			return i18n.Errorf("error.select_idx", handIdxs)
+		}
+		seen[handIdx] = true
+	}
+	g.Selected = append([]int{}, handIdxs...)
+	return nil
+}
+
+// 結算時算入的手牌位置, 玩家有選擇時為選擇的5張, 否則為全部手牌
+func (g *CardGame) settlementIdxs() []int {
+	if g.ChooseHand && len(g.Selected) == 5 {
+		return g.Selected
+	}
+	idxs := make([]int, len(g.HandCards))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	return idxs
+}
+
+// SettlementCards 結算時算入的手牌, 超過5張時評估會從中選出最大的5張組合
+//
+// 沒有選牌時直接回傳 HandCards, 不可修改
+func (g *CardGame) SettlementCards() []*card.Card {
+	if !g.ChooseHand || len(g.Selected) != 5 {
+		return g.HandCards
+	}
+	idxs := g.settlementIdxs()
+	cards := make([]*card.Card, len(idxs))
+	for i, idx := range idxs {
+		cards[i] = g.HandCards[idx]
+	}
+	return cards
 }
 
+// GetHandType 目前結算手牌的牌型, 標準牌組查表不配置記憶體; 需要踢腳時用 GetHandRank
 func (g *CardGame) GetHandType() card.HandType {
-	return card.GetHandType(g.HandCards)
+	return g.Rules.GetHandType(g.SettlementCards())
 }
 
+// GetHandRank 取得目前結算手牌完整的評比結果(含踢腳), 可用來跟其他手牌比大小
+func (g *CardGame) GetHandRank() card.HandRank {
+	return g.Rules.Evaluate(g.SettlementCards())
+}
+
+// ShowCards 顯示手牌, 組成目前牌型的牌會加上 * 標示
 func (g *CardGame) ShowCards() {
-	cardStr := "手牌: "
-	for _, card := range MyGame.HandCards {
-		cardStr += fmt.Sprintf("[%v] ", card.ToString())
+	idxs := g.settlementIdxs()
+	result := g.Rules.Explain(g.SettlementCards())
+	contributing := make(map[int]bool)
+	for i, idx := range idxs {
+		contributing[idx] = result.IsContributing(i)
+	}
+	cardStr := g.msg("game.hand")
+	for i, c := range g.HandCards {
+		if result.Type != card.HighCard && contributing[i] {
+			cardStr += fmt.Sprintf("[%v]* ", c.ToString())
+		} else {
+			cardStr += fmt.Sprintf("[%v] ", c.ToString())
+		}
+	}
+	cardStr += g.msg("game.hand_type", result.Type.Name(g.locale()))
+	g.println(cardStr)
+}
+
+// 玩家設定的語系, 沒有玩家時使用預設語系
+func (g *CardGame) locale() i18n.Locale {
+	if g.Player == nil || g.Player.Locale == "" {
+		return i18n.DefaultLocale
+	}
+	return g.Player.Locale
+}
+
+// 依玩家語系取得遊戲訊息
+func (g *CardGame) msg(key string, args ...any) string {
+	return i18n.T(g.locale(), key, args...)
+}
+
+// SolveHold 依目前的手牌、規則、賠率表與換牌花費計算所有換牌選擇的期望值, 第一筆為最佳選擇
+//
+// 這局已經換掉的牌不在牌堆中, 不會被算成可能抽到的牌
+func (g *CardGame) SolveHold() ([]analysis.HoldResult, error) {
+	analyzer := analysis.NewAnalyzer(g.Rules, g.Paytable)
+	return analyzer.SolveHold(g.HandCards, g.curDiscardCost(), g.deadCards())
+}
+
+// PlanDiscard 考慮之後還能繼續換牌與逐次增加的花費, 計算目前該結算還是換掉哪幾張牌
+//
+// maxRounds 為一局最多換幾次牌(含已經換過的次數), 0 表示不限制
+func (g *CardGame) PlanDiscard(maxRounds int) (analysis.Decision, error) {
+	planner := analysis.NewPlanner(
+		analysis.NewAnalyzer(g.Rules, g.Paytable),
+		analysis.DiscardCost{Default: g.DefaultDiscardCost, Add: g.DiscardAddCost},
+		maxRounds,
+	)
+	return planner.Decide(g.AnalysisState())
+}
+
+// GameConfig 目前牌局的花費與手牌張數, 給 RTP、策略表等分析使用
+func (g *CardGame) GameConfig(maxRounds int) analysis.GameConfig {
+	return analysis.GameConfig{
+		GameCost:           g.GameCost,
+		DefaultDiscardCost: g.DefaultDiscardCost,
+		DiscardAddCost:     g.DiscardAddCost,
+		HandSize:           g.handSize(),
+		MaxRounds:          maxRounds,
+	}
+}
+
+// AnalysisState 目前牌局給策略分析用的狀態
+func (g *CardGame) AnalysisState() analysis.State {
+	return analysis.State{
+		Hand:         g.HandCards,
+		Dead:         g.deadCards(),
+		DiscardCount: g.CurDiscardCount,
+	}
+}
+
+// 不在手牌也不在牌堆中的牌, 也就是這局已經換掉的牌
+func (g *CardGame) deadCards() []*card.Card {
+	deck, hand := g.deckPile.counts, g.handPile.counts
+	dead := []*card.Card{}
+	for _, c := range g.Rules.NewDeck() {
+		if hand[c.Idx] > 0 {
+			hand[c.Idx]--
+			continue
+		}
+		if deck[c.Idx] > 0 {
+			deck[c.Idx]--
+			continue
+		}
+		dead = append(dead, c)
 	}
-	cardStr += "   目前牌型: " + g.GetHandType().ToString()
-	fmt.Println(cardStr)
+	return dead
 }
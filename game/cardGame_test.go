@@ -0,0 +1,250 @@
+package game
+
+import (
+	"io"
+	"math-discard-card/card"
+	"math-discard-card/i18n"
+	"math-discard-card/utility"
+	"reflect"
+	"testing"
+)
+
+func newTestGame(handSize int, chooseHand bool) *CardGame {
+	g := &CardGame{
+		Player:     NewPlayer(100),
+		GameCost:   1,
+		HandSize:   handSize,
+		ChooseHand: chooseHand,
+		Rules:      card.StandardRules,
+		Paytable:   card.DefaultPaytable(),
+		Output:     io.Discard,
+	}
+	g.SetSeed(1)
+	return g
+}
+
+func TestNewGameHandSize(t *testing.T) {
+	tests := []struct {
+		handSize int
+		handIdxs []int
+		expected int
+	}{
+		{0, nil, 5},
+		{5, nil, 5},
+		{7, nil, 7},
+		{7, []int{1, 2}, 7},
+		{0, []int{1, 2}, 5},
+	}
+
+	for _, tt := range tests {
+		g := newTestGame(tt.handSize, false)
+		g.NewGame(tt.handIdxs...)
+		if len(g.HandCards) != tt.expected {
+			t.Errorf("HandSize %d with %v: expected %d cards, got %d", tt.handSize, tt.handIdxs, tt.expected, len(g.HandCards))
+		}
+		if len(g.Deck)+len(g.HandCards) != 52 {
+			t.Errorf("HandSize %d with %v: expected 52 cards in total, got %d", tt.handSize, tt.handIdxs, len(g.Deck)+len(g.HandCards))
+		}
+	}
+}
+
+func TestSelectCards(t *testing.T) {
+	g := newTestGame(7, true)
+	g.NewGame()
+	// 四條 A 加上 K♠、Q♠、J♠, 自動結算取最大的5張
+	if err := g.SetHand(card.MustParseHand("As Ah Ad Ac Ks Qs Js")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handType := g.GetHandType(); handType != card.FourOfAKind {
+		t.Errorf("expected best 5 of 7 to be four of a kind, got %s", handType.ToString())
+	}
+
+	invalid := [][]int{{0, 1, 2, 3}, {0, 1, 2, 3, 3}, {0, 1, 2, 3, 7}}
+	for _, idxs := range invalid {
+		if err := g.SelectCards(idxs...); err == nil {
+			t.Errorf("expected SelectCards(%v) to fail", idxs)
+		}
+	}
+	if err := g.SelectCards(0, 1, 4, 5, 6); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handType := g.GetHandType(); handType != card.Pair {
+		t.Errorf("expected selected cards to be a pair, got %s", handType.ToString())
+	}
+	if cards := g.SettlementCards(); !reflect.DeepEqual(cards, card.MustParseHand("As Ah Ks Qs Js")) {
+		t.Errorf("unexpected settlement cards %v", cards)
+	}
+
+	g.Settlement()
+	if g.Player.Pt != 100-1+card.DefaultPaytable().Payout(card.Pair) {
+		t.Errorf("expected settlement to pay a pair, got %d points", g.Player.Pt)
+	}
+
+	// 換牌後要重新選擇
+	g.DiscardCard(6)
+	if g.Selected != nil {
+		t.Errorf("expected selection to be cleared after discarding, got %v", g.Selected)
+	}
+
+	g = newTestGame(7, false)
+	g.NewGame()
+	if err := g.SelectCards(0, 1, 2, 3, 4); err == nil {
+		t.Errorf("expected SelectCards to fail when ChooseHand is off")
+	}
+}
+
+func TestGetHandType(t *testing.T) {
+	hands := []string{"As Ah Ad Ac Ks", "Ts Js Qs Ks As", "2c 3d 4h 5s 7c", "As Ah Ad Ac Ks Qs Js"}
+	for _, hand := range hands {
+		g := newTestGame(len(card.MustParseHand(hand)), false)
+		g.NewGame()
+		if err := g.SetHand(card.MustParseHand(hand)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if handType, rank := g.GetHandType(), g.GetHandRank(); handType != rank.Type {
+			t.Errorf("%s: GetHandType %s disagrees with GetHandRank %s", hand, handType.ToString(), rank.Type.ToString())
+		}
+		// 標準牌組沒有選牌時查表, 不配置記憶體
+		if allocs := testing.AllocsPerRun(100, func() { g.GetHandType() }); allocs != 0 {
+			t.Errorf("%s: expected 0 allocs, got %v", hand, allocs)
+		}
+	}
+}
+
+func TestSettlementPayTo(t *testing.T) {
+	// x to 1 退還的押注是開局花費, 不是固定1點
+	g := newTestGame(5, false)
+	g.GameCost = 10
+	if err := g.SetPaytable(&card.Paytable{Version: "to", Mode: card.PayTo, Pays: map[card.HandType]int{card.Pair: 1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.NewGame()
+	if err := g.SetHand(card.MustParseHand("As Ah Kd Qc 9h")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Settlement()
+	if g.Player.Pt != 100-10+1+10 {
+		t.Errorf("expected a 1 to 1 pair to return the 10 point stake, got %d points", g.Player.Pt)
+	}
+}
+
+func TestSolveHoldAfterDiscard(t *testing.T) {
+	g := newTestGame(5, false)
+	g.NewGame()
+	g.DiscardCard(0, 1, 2)
+	if len(g.Deck) != 44 {
+		t.Fatalf("expected 44 cards left in the deck, got %d", len(g.Deck))
+	}
+	results, err := g.SolveHold()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 換掉的牌不能再抽到, 每種換牌選擇的抽法都從剩下的44張計算
+	for _, result := range results {
+		if expected := utility.Binomial(len(g.Deck), len(result.Discard)); result.Outcomes.Total != expected {
+			t.Errorf("discard %v: expected %d draws from the remaining deck, got %d", result.Discard, expected, result.Outcomes.Total)
+		}
+	}
+}
+
+func TestNoPlayer(t *testing.T) {
+	g := newTestGame(5, false)
+	g.Player = nil
+	if err := g.NewGame(); !isErrorKey(err, "error.no_player") {
+		t.Errorf("expected NewGame without a player to fail, got %v", err)
+	}
+	if len(g.HandCards) != 0 {
+		t.Errorf("expected no cards to be dealt without a player, got %v", g.HandCards)
+	}
+	if err := g.DiscardCard(0); !isErrorKey(err, "error.no_player") {
+		t.Errorf("expected DiscardCard without a player to fail, got %v", err)
+	}
+	if err := g.Settlement(); !isErrorKey(err, "error.no_player") {
+		t.Errorf("expected Settlement without a player to fail, got %v", err)
+	}
+}
+
+func isErrorKey(err error, key string) bool {
+	e, ok := err.(*i18n.Error)
+	return ok && e.Key == key
+}
+
+func TestCardSetsInSync(t *testing.T) {
+	doubleDeck := &card.Rules{Deck: card.DeckSpec{Copies: 2}}
+	tests := []struct {
+		name  string
+		rules *card.Rules
+	}{
+		{"Standard", card.StandardRules},
+		{"Jokers", card.JokerPokerRules},
+		{"TwoDecks", doubleDeck},
+	}
+
+	for _, tt := range tests {
+		g := newTestGame(7, false)
+		g.Rules = tt.rules
+		g.NewGame()
+		for round := 0; round < 3; round++ {
+			if g.RemainingSet() != card.NewCardSet(g.Deck...) {
+				t.Errorf("%s round %d: expected RemainingSet to match the deck", tt.name, round)
+			}
+			if g.HandSet() != card.NewCardSet(g.HandCards...) {
+				t.Errorf("%s round %d: expected HandSet to match the hand", tt.name, round)
+			}
+			if dead := len(g.deadCards()); dead != round*2 {
+				t.Errorf("%s round %d: expected %d dead cards, got %d", tt.name, round, round*2, dead)
+			}
+			g.DiscardCard(0, 6)
+		}
+	}
+
+	g := newTestGame(5, false)
+	g.NewGame()
+	if err := g.SetHand(card.MustParseHand("As Ah Ad Ac Ks")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.HandSet() != card.NewCardSet(g.HandCards...) || g.RemainingSet() != card.NewCardSet(g.Deck...) {
+		t.Errorf("expected SetHand to keep the sets in sync")
+	}
+	if len(g.Deck) != 47 || g.RemainingSet().Contains(card.MustParseHand("As")[0]) {
+		t.Errorf("expected SetHand to remove the hand from the deck, got %d cards", len(g.Deck))
+	}
+}
+
+func TestSetHandNotInDeck(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules *card.Rules
+		hand  string
+	}{
+		{"DuplicateInSingleDeck", card.StandardRules, "As As Kd Qc Jh"},
+		{"MissingRankInShortDeck", &card.Rules{Deck: card.ShortDeck}, "2s As Kd Qc Jh"},
+	}
+	for _, tt := range tests {
+		g := newTestGame(5, false)
+		g.Rules = tt.rules
+		g.NewGame()
+		hand, deck := append([]*card.Card{}, g.HandCards...), len(g.Deck)
+		if err := g.SetHand(card.MustParseHand(tt.hand)); !isErrorKey(err, "error.set_hand") {
+			t.Errorf("%s: expected SetHand to fail, got %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(g.HandCards, hand) || len(g.Deck) != deck || len(g.deadCards()) != 0 {
+			t.Errorf("%s: expected the hand and deck to be unchanged", tt.name)
+		}
+	}
+}
+
+func TestSelectCardsErrorLocale(t *testing.T) {
+	g := newTestGame(7, true)
+	g.NewGame()
+	err := g.SelectCards(0, 1)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if got := i18n.Message(i18n.En, err); got != "Choose 5 cards, got 2" {
+		t.Errorf("unexpected en message %q", got)
+	}
+	if got := err.Error(); got != "要選擇5張手牌, 目前選了 2 張" {
+		t.Errorf("unexpected default message %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"io"
+	"math-discard-card/card"
+	"math-discard-card/i18n"
+	"math-discard-card/utility"
+	"sync/atomic"
+)
+
+// Config 每個 Session 共用的遊戲設定
+type Config struct {
+	GameCost           int
+	DefaultDiscardCost int
+	DiscardAddCost     int
+	HandSize           int            // 手牌張數, 0 為5張
+	ChooseHand         bool           // 手牌超過5張時由玩家選擇結算的5張
+	Rules              *card.Rules    // 玩法規則, nil 為標準規則
+	Paytable           *card.Paytable // 賠率表, nil 為預設賠率表
+	Locale             i18n.Locale    // 新玩家的語系, 空白為預設語系
+	RNG                string         // 洗牌用的亂數演算法(pcg、chacha8、crypto), 空白為 pcg
+}
+
+// Engine 建立互相獨立的 Session, 每個 Session 有自己的牌組、玩家與亂數
+//
+// Engine 可以同時在多個 goroutine 中建立 Session; 規則與賠率表由所有 Session 共用, 建立後不可再修改
+type Engine struct {
+	config Config
+	Output io.Writer // 新 Session 的遊戲訊息輸出, nil 時為標準輸出
+	lastID atomic.Int64
+}
+
+// Session 一位玩家的遊戲, 同一個 Session 同時只能在一個 goroutine 中使用, 不同 Session 可以同時使用
+type Session struct {
+	*CardGame
+	ID int64
+}
+
+// NewEngine 檢查設定並建立遊戲引擎
+func NewEngine(config Config) (*Engine, error) {
+	if config.Rules == nil {
+		config.Rules = card.StandardRules
+	}
+	if config.Paytable == nil {
+		config.Paytable = card.DefaultPaytable()
+	}
+	if err := config.Rules.Deck.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Paytable.Validate(); err != nil {
+		return nil, err
+	}
+	config.Paytable = config.Paytable.WithStake(config.GameCost)
+	if config.HandSize != 0 && (config.HandSize < 5 || config.HandSize > len(config.Rules.NewDeck())) {
+		return nil, i18n.Errorf("error.hand_size", config.HandSize)
+	}
+	if _, err := utility.NewRNG(config.RNG, 0); err != nil {
+		return nil, err
+	}
+	if config.Locale == "" {
+		config.Locale = i18n.DefaultLocale
+	}
+	return &Engine{config: config}, nil
+}
+
+// Config 引擎的遊戲設定
+func (e *Engine) Config() Config {
+	return e.config
+}
+
+// NewSession 建立一位起始點數為 pt 的玩家, 以設定的亂數演算法與 seed 洗牌,
+// 使用 pcg、chacha8 時相同種子與操作會得到相同的牌局
+//
+// 建立後還沒有發牌, 要先呼叫 NewGame 開局
+func (e *Engine) NewSession(pt int, seed int64) *Session {
+	// 設定已經在 NewEngine 檢查過
+	rng, _ := utility.NewRNG(e.config.RNG, seed)
+	return e.NewSessionRNG(pt, rng)
+}
+
+// NewSessionRNG 同 NewSession, 直接指定洗牌用的亂數來源, 亂數來源不可與其他 Session 共用(CryptoRNG 除外)
+func (e *Engine) NewSessionRNG(pt int, rng utility.RNG) *Session {
+	g := &CardGame{
+		GameCost:           e.config.GameCost,
+		DefaultDiscardCost: e.config.DefaultDiscardCost,
+		DiscardAddCost:     e.config.DiscardAddCost,
+		HandSize:           e.config.HandSize,
+		ChooseHand:         e.config.ChooseHand,
+		Player: &Player{
+			Pt:     pt,
+			Locale: e.config.Locale,
+		},
+		Rules:    e.config.Rules,
+		Paytable: e.config.Paytable,
+		RNG:      utility.WrapRNG(rng),
+		Output:   e.Output,
+	}
+	g.initDeck()
+	return &Session{
+		CardGame: g,
+		ID:       e.lastID.Add(1),
+	}
+}
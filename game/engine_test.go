@@ -0,0 +1,105 @@
+package game
+
+import (
+	"io"
+	"math-discard-card/card"
+	"math-discard-card/utility"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNewEngine(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{"Default", Config{GameCost: 10}, true},
+		{"SevenCards", Config{HandSize: 7}, true},
+		{"TooFewCards", Config{HandSize: 4}, false},
+		{"TooManyCards", Config{HandSize: 53}, false},
+		{"InvalidPaytable", Config{Paytable: &card.Paytable{Version: "empty"}}, false},
+		{"ChaCha8", Config{RNG: "chacha8"}, true},
+		{"Crypto", Config{RNG: "crypto"}, true},
+		{"UnknownRNG", Config{RNG: "mt19937"}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := NewEngine(tt.config)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// 以同樣的種子玩幾局並記錄每局的手牌與點數
+func playSession(engine *Engine, seed int64) ([]string, int) {
+	session := engine.NewSession(100, seed)
+	hands := []string{}
+	for i := 0; i < 20; i++ {
+		session.NewGame()
+		session.DiscardCard(0, 1)
+		hands = append(hands, card.NewCardSet(session.HandCards...).ToString())
+		session.Settlement()
+	}
+	return hands, session.Player.Pt
+}
+
+func TestEngineSessions(t *testing.T) {
+	engine, err := NewEngine(Config{GameCost: 1, DefaultDiscardCost: 1, DiscardAddCost: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	engine.Output = io.Discard
+
+	expectedHands, expectedPt := playSession(engine, 42)
+
+	// 同時玩多個 Session, 互不影響
+	var wg sync.WaitGroup
+	results := make([][]string, 8)
+	pts := make([]int, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], pts[i] = playSession(engine, 42)
+		}(i)
+	}
+	wg.Wait()
+	for i := range results {
+		if !reflect.DeepEqual(results[i], expectedHands) || pts[i] != expectedPt {
+			t.Errorf("session %d: expected the same games for the same seed", i)
+		}
+	}
+
+	// 使用 ChaCha8 時同樣可以重播, 但與 PCG 的牌局不同
+	chacha, err := NewEngine(Config{GameCost: 1, DefaultDiscardCost: 1, DiscardAddCost: 1, RNG: "chacha8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chacha.Output = io.Discard
+	first, _ := playSession(chacha, 42)
+	second, _ := playSession(chacha, 42)
+	if !reflect.DeepEqual(first, second) || reflect.DeepEqual(first, expectedHands) {
+		t.Errorf("expected ChaCha8 sessions to be reproducible and differ from PCG")
+	}
+
+	firstSession, secondSession := engine.NewSession(10, 1), engine.NewSession(20, 1)
+	if firstSession.ID == secondSession.ID {
+		t.Errorf("expected unique session IDs, got %d twice", firstSession.ID)
+	}
+	firstSession.NewGame()
+	if firstSession.Player.Pt != 9 || secondSession.Player.Pt != 20 {
+		t.Errorf("expected independent players, got %d and %d", firstSession.Player.Pt, secondSession.Player.Pt)
+	}
+
+	crypto := engine.NewSessionRNG(10, utility.NewCryptoRNG())
+	crypto.NewGame()
+	if len(crypto.HandCards) != 5 || len(crypto.Deck) != 47 {
+		t.Errorf("expected crypto session to deal 5 cards, got %d", len(crypto.HandCards))
+	}
+}
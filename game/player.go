@@ -1,14 +1,17 @@
 package game
 
+import "math-discard-card/i18n"
+
 type Player struct {
-	Pt int
+	Pt     int
+	Locale i18n.Locale // 遊戲訊息顯示的語系
 }
 
-var MyPlayer *Player
-
-func NewPlayer(pt int) {
-	MyPlayer = &Player{
-		Pt: 100,
+// NewPlayer 建立起始點數為 pt、使用預設語系的玩家
+func NewPlayer(pt int) *Player {
+	return &Player{
+		Pt:     pt,
+		Locale: i18n.DefaultLocale,
 	}
 }
 
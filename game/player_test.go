@@ -0,0 +1,10 @@
+package game
+
+import "testing"
+
+func TestNewPlayer(t *testing.T) {
+	player := NewPlayer(37)
+	if player.Pt != 37 {
+		t.Errorf("expected NewPlayer to use the given points, got %d", player.Pt)
+	}
+}
@@ -2,6 +2,9 @@ module math-discard-card
 
 go 1.22.3
 
-require github.com/sirupsen/logrus v1.9.3
+require (
+	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
@@ -0,0 +1,59 @@
+package i18n
+
+// Named 可以依語系取得名稱的值, 例如牌型; 當作錯誤參數時會以錯誤的語系顯示
+type Named interface {
+	Name(locale Locale) string
+}
+
+// Error 以訊息 key 與參數記錄的錯誤, 顯示時才依語系翻譯
+//
+// Error() 使用預設語系, 面對玩家的地方(指令列、互動遊玩)以 Message 轉成玩家的語系
+type Error struct {
+	Key  string
+	Args []any
+}
+
+// Errorf 建立可翻譯的錯誤, 參數中的 error 會被包裝, 可以用 errors.Is、errors.As 取得;
+// 訊息中對應 error 的參數要用 %v
+func Errorf(key string, args ...any) error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return e.Localize(DefaultLocale)
+}
+
+// Localize 依語系取得錯誤訊息, 參數中的 error 與 Named 也會一併翻譯
+func (e *Error) Localize(locale Locale) string {
+	args := make([]any, len(e.Args))
+	for i, arg := range e.Args {
+		switch arg := arg.(type) {
+		case error:
+			args[i] = Message(locale, arg)
+		case Named:
+			args[i] = arg.Name(locale)
+		default:
+			args[i] = arg
+		}
+	}
+	return T(locale, e.Key, args...)
+}
+
+// Unwrap 參數中包裝的 error
+func (e *Error) Unwrap() []error {
+	var errs []error
+	for _, arg := range e.Args {
+		if err, ok := arg.(error); ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Message 依語系取得錯誤訊息, 不是 *Error 時直接回傳 err.Error()
+func Message(locale Locale, err error) string {
+	if e, ok := err.(*Error); ok {
+		return e.Localize(locale)
+	}
+	return err.Error()
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Locale 語系
+type Locale string
+
+const (
+	ZhTW Locale = "zh-TW"
+	En   Locale = "en"
+)
+
+// DefaultLocale 沒有指定語系或找不到訊息時使用的語系
+var DefaultLocale = ZhTW
+
+var (
+	mutex    sync.RWMutex
+	catalogs = map[Locale]map[string]string{
+		ZhTW: zhTWMessages,
+		En:   enMessages,
+	}
+)
+
+// ParseLocale 解析語系, 不分大小寫, 底線與連字號視為相同, 例如 "zh_tw"、"EN"
+func ParseLocale(str string) (Locale, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(str), "_", "-")
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for locale := range catalogs {
+		if strings.EqualFold(normalized, string(locale)) {
+			return locale, nil
+		}
+	}
+	return "", Errorf("error.locale", str)
+}
+
+// Register 新增或覆寫某個語系的訊息
+func Register(locale Locale, messages map[string]string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	catalog := make(map[string]string)
+	for key, message := range catalogs[locale] {
+		catalog[key] = message
+	}
+	for key, message := range messages {
+		catalog[key] = message
+	}
+	catalogs[locale] = catalog
+}
+
+// T 依語系取得訊息並以 fmt.Sprintf 套用參數, 該語系沒有此訊息時改用預設語系, 都沒有時回傳 key
+func T(locale Locale, key string, args ...any) string {
+	mutex.RLock()
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[DefaultLocale][key]
+	}
+	mutex.RUnlock()
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
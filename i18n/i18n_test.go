@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for key := range zhTWMessages {
+		if _, ok := enMessages[key]; !ok {
+			t.Errorf("en is missing %q", key)
+		}
+	}
+	for key := range enMessages {
+		if _, ok := zhTWMessages[key]; !ok {
+			t.Errorf("zh-TW is missing %q", key)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(En, "game.settlement", "Pair", 2, 98); got != "Settled hand: Pair  Points won: 2   Player points: 98" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	if got := T(ZhTW, "hand.Pair"); got != "對子" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	// 不支援的語系改用預設語系, 找不到 key 時回傳 key
+	if got := T(Locale("fr"), "hand.Pair"); got != "對子" {
+		t.Errorf("Expected fallback to default locale, got %q", got)
+	}
+	if got := T(En, "missing.key"); got != "missing.key" {
+		t.Errorf("Expected key fallback, got %q", got)
+	}
+}
+
+func TestParseLocaleAndRegister(t *testing.T) {
+	tests := []struct {
+		input    string
+		locale   Locale
+		expected bool // Expecting no error
+	}{
+		{"zh-TW", ZhTW, true},
+		{"zh_tw", ZhTW, true},
+		{"EN", En, true},
+		{"fr", "", false},
+	}
+	for _, tt := range tests {
+		locale, err := ParseLocale(tt.input)
+		if (err == nil) != tt.expected || locale != tt.locale {
+			t.Errorf("%q: got %q, %v", tt.input, locale, err)
+		}
+	}
+
+	Register(Locale("ja"), map[string]string{"hand.Pair": "ワンペア"})
+	if got := T(Locale("ja"), "hand.Pair"); got != "ワンペア" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	if got := T(Locale("ja"), "hand.Flush"); got != "同花" {
+		t.Errorf("Expected fallback to default locale, got %q", got)
+	}
+	if _, err := ParseLocale("ja"); err != nil {
+		t.Errorf("Expected registered locale to parse, got %v", err)
+	}
+}
+
+type testName string
+
+func (n testName) Name(locale Locale) string {
+	return string(n) + "@" + string(locale)
+}
+
+func TestError(t *testing.T) {
+	inner := Errorf("error.parse_card", "Xx")
+	err := Errorf("error.parse_hand", "As Xx", inner)
+	if got := err.Error(); got != `解析手牌 "As Xx" 失敗: 無法解析的牌: "Xx"` {
+		t.Errorf("Unexpected default message %q", got)
+	}
+	if got := Message(En, err); got != `Failed to parse hand "As Xx": Cannot parse card: "Xx"` {
+		t.Errorf("Unexpected en message %q", got)
+	}
+	if !errors.Is(err, inner) {
+		t.Errorf("Expected wrapped error to be found with errors.Is")
+	}
+	if got := Message(En, Errorf("error.paytable_negative", "v1", testName("Pair"), -1)); got != "Paytable v1: the pay for Pair@en cannot be negative: -1" {
+		t.Errorf("Expected Named arguments to be localized, got %q", got)
+	}
+	plain := errors.New("plain")
+	if got := Message(En, plain); got != "plain" {
+		t.Errorf("Expected plain errors unchanged, got %q", got)
+	}
+
+	// 每個 error.* 訊息都有對應的英文
+	for key := range zhTWMessages {
+		if strings.HasPrefix(key, "error.") && enMessages[key] == zhTWMessages[key] {
+			t.Errorf("%q is not translated", key)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package i18n
+
+// 內建的訊息, 新增 key 時兩個語系都要補上
+
+var zhTWMessages = map[string]string{
+	"suit.clubs":    "梅花",
+	"suit.diamonds": "方塊",
+	"suit.hearts":   "紅心",
+	"suit.spades":   "黑桃",
+	"suit.joker":    "鬼牌",
+
+	"hand.HighCard":      "高牌",
+	"hand.Pair":          "對子",
+	"hand.TwoPair":       "兩對",
+	"hand.ThreeOfAKind":  "三條",
+	"hand.Straight":      "順子",
+	"hand.Flush":         "同花",
+	"hand.FullHouse":     "葫蘆",
+	"hand.FourOfAKind":   "四條",
+	"hand.StraightFlush": "同花順",
+	"hand.FiveOfAKind":   "五條",
+	"hand.RoyalFlush":    "同花大順",
+	"undefined":          "尚未定義",
+
+	"game.new":           "新的一局遊戲 花費%v點遊玩 玩家點數: %v",
+	"game.no_card_idx":   "牌池無此idx的牌: %d",
+	"game.settlement":    "結算牌型: %v  獲得點數: %v   玩家點數: %v",
+	"game.invalid_args":  "傳入參數錯誤",
+	"game.not_enough_pt": "點數不夠",
+	"game.discard_cost":  "重抽花費點數%v  玩家點數: %v",
+	"game.discard":       "丟棄: %v",
+	"game.draw":          "  抽到: %v",
+	"game.hand":          "手牌: ",
+	"game.hand_type":     "   目前牌型: %v",
+
+	"error.locale":             "不支援的語系: %q",
+	"error.parse_suit":         "無法解析的花色: %q",
+	"error.parse_card":         "無法解析的牌: %q",
+	"error.parse_hand":         "解析手牌 %q 失敗: %v",
+	"error.missing_card":       "缺少牌的內容",
+	"error.missing_suit":       "缺少花色: %q",
+	"error.missing_number":     "缺少點數",
+	"error.parse_number":       "無法解析的點數: %q",
+	"error.marshal_card":       "無法轉成文字的牌: %+v",
+	"error.marshal_suit":       "無法轉成文字的花色: %d",
+	"error.parse_hand_type":    "無法解析的牌型: %q",
+	"error.marshal_hand_type":  "無法轉成文字的牌型: %d",
+	"error.legacy_hand_type":   "未定義的舊版牌型: %d",
+	"error.deck_numbers":       "牌組點數設定錯誤: %v",
+	"error.deck_suits":         "牌組花色設定錯誤: %v",
+	"error.deck_copies":        "牌組副數設定錯誤: %d",
+	"error.paytable_read":      "讀取賠率表 %s 失敗: %v",
+	"error.paytable_format":    "不支援的賠率表格式: %s",
+	"error.paytable_parse":     "解析賠率表失敗: %v",
+	"error.paytable_version":   "賠率表缺少版本",
+	"error.paytable_mode":      "賠率表 %s 的計算方式錯誤: %q",
+	"error.paytable_empty":     "賠率表 %s 沒有任何牌型",
+	"error.paytable_hand_type": "賠率表 %s 有未定義的牌型: %d",
+	"error.paytable_negative":  "賠率表 %s 的 %v 賠率不可為負數: %d",
+	"error.hand_size":          "手牌張數設定錯誤: %d",
+	"error.select_disabled":    "目前不能選擇結算的手牌",
+	"error.select_count":       "要選擇5張手牌, 目前選了 %d 張",
+	"error.select_idx":         "手牌位置錯誤: %v",
+	"error.empty_hand":         "手牌不可為空",
+	"error.hand_flag":          "要以 -hand 指定 %d 張手牌, 目前為 %d 張",
+	"error.no_player":          "牌局沒有設定玩家",
+	"error.set_hand":           "牌堆中沒有 %s, 無法放入手牌",
+	"error.not_enough_cards":   "剩餘牌堆只有 %d 張, 不夠換 %d 張",
+	"error.discard_range":      "換牌位置超出手牌範圍: %d",
+	"error.discard_duplicate":  "換牌位置重複: %d",
+	"error.dead_card":          "牌組中沒有足夠的牌可以扣除: Idx %d",
+	"error.min_hand_size":      "手牌至少要 5 張, 目前設定為 %d 張",
+	"error.deck_too_small":     "牌組只有 %d 張, 不夠發 %d 張手牌",
+	"error.strategy":           "未定義的策略: %q",
+	"error.end_reason":         "無法轉成文字的結束原因: %d",
+	"error.sessions":           "模擬次數必須大於0: %d",
+	"error.max_games":          "每次遊玩的局數上限必須大於0: %d",
+	"error.no_strategy":        "缺少模擬策略",
+	"error.rounds":             "模擬局數必須大於0: %d",
+	"error.rules":              "未定義的玩法規則: %q",
+	"error.rng":                "未定義的亂數演算法: %q",
+	"error.simulation_rng":     "模擬要能以種子重現, 不能使用亂數演算法 %q",
+	"error.index":              "索引輸入錯誤: %q",
+	"error.format":             "不支援的輸出格式: %q",
+
+	"cli.commands":      "============指令清單============ \n1. reset(重置遊戲), \n2. play(開始遊戲), \n3. d-0,2(換第1與第3張手牌), \n4. s-0,1,2,3,4(選擇結算的5張手牌, 需開啟 -choose-hand), \n5. lang-en(切換語系)",
+	"cli.prompt":        "請輸入指令: ",
+	"cli.invalid_input": "輸入錯誤",
+	"cli.need_idx":      "要輸入想替換的手牌索引",
+	"cli.invalid_idx":   "索引輸入錯誤: %v",
+	"cli.reset":         "重置遊戲",
+	"cli.locale":        "語系已切換為: %v",
+
+	"cli.usage":    "用法: math-discard-card <指令> [參數]\n\n指令:\n  play      互動式遊玩(預設)\n  odds      換牌後的牌型分布\n  ev        所有換牌選擇的期望值\n  rtp       整個遊戲的理論回報\n  simulate  蒙地卡羅模擬\n  chart     所有起手牌的最佳留牌策略表\n\n使用 math-discard-card <指令> -h 查看參數",
+	"cli.unknown":  "未定義的指令: %v",
+	"cli.error":    "錯誤: %v",
+	"cli.progress": "計算進度: %d/%d (%.1f%%)",
+
+	"odds.header": "手牌: %v  換掉位置: %v  總組合數: %d",
+	"odds.row":    "%v\t%d\t%v\t%v\t%v",
+
+	"ev.header": "換牌花費: %d",
+	"ev.row":    "%v換掉位置: %v\t期望拿回: %v\t淨期望值: %v",
+	"ev.plan":   "考慮之後最多換 %d 次: %v, 期望淨點數 %v",
+	"ev.settle": "結算",
+	"ev.draw":   "換掉位置 %v",
+
+	"rtp.strategy":   "策略: %v  起手牌組合數: %d",
+	"rtp.payout":     "每局期望拿回: %v  每局期望花費: %v",
+	"rtp.rtp":        "RTP: %v  莊家優勢: %v  標準差: %.6f",
+	"rtp.hit":        "%v\t%v",
+	"rtp.hit_header": "牌型出現機率:",
+
+	"simulate.header":     "策略: %v  局數: %d  種子: %d",
+	"simulate.rtp":        "RTP: %v  95%%信賴區間: [%.6f%%, %.6f%%]",
+	"simulate.metric":     "%v\t平均: %.6f\t標準差: %.6f\t95%%信賴區間: [%.6f, %.6f]\t百分位數(1/5/25/50/75/95/99): %v",
+	"simulate.net":        "淨輸贏",
+	"simulate.payout":     "拿回點數",
+	"simulate.cost":       "花費",
+	"simulate.discards":   "換牌次數",
+	"simulate.hit":        "%v\t%v\t95%%信賴區間: [%.6f, %.6f]",
+	"simulate.hit_header": "牌型出現機率:",
+}
+
+var enMessages = map[string]string{
+	"suit.clubs":    "Clubs",
+	"suit.diamonds": "Diamonds",
+	"suit.hearts":   "Hearts",
+	"suit.spades":   "Spades",
+	"suit.joker":    "Joker",
+
+	"hand.HighCard":      "High Card",
+	"hand.Pair":          "Pair",
+	"hand.TwoPair":       "Two Pair",
+	"hand.ThreeOfAKind":  "Three of a Kind",
+	"hand.Straight":      "Straight",
+	"hand.Flush":         "Flush",
+	"hand.FullHouse":     "Full House",
+	"hand.FourOfAKind":   "Four of a Kind",
+	"hand.StraightFlush": "Straight Flush",
+	"hand.FiveOfAKind":   "Five of a Kind",
+	"hand.RoyalFlush":    "Royal Flush",
+	"undefined":          "Undefined",
+
+	"game.new":           "New game, cost %v points to play. Player points: %v",
+	"game.no_card_idx":   "No card with idx %d in the deck",
+	"game.settlement":    "Settled hand: %v  Points won: %v   Player points: %v",
+	"game.invalid_args":  "Invalid arguments",
+	"game.not_enough_pt": "Not enough points",
+	"game.discard_cost":  "Discard cost %v points  Player points: %v",
+	"game.discard":       "Discarded: %v",
+	"game.draw":          "  Drew: %v",
+	"game.hand":          "Hand: ",
+	"game.hand_type":     "   Current hand: %v",
+
+	"error.locale":             "Unsupported language: %q",
+	"error.parse_suit":         "Cannot parse suit: %q",
+	"error.parse_card":         "Cannot parse card: %q",
+	"error.parse_hand":         "Failed to parse hand %q: %v",
+	"error.missing_card":       "Missing card",
+	"error.missing_suit":       "Missing suit: %q",
+	"error.missing_number":     "Missing rank",
+	"error.parse_number":       "Cannot parse rank: %q",
+	"error.marshal_card":       "Cannot convert card to text: %+v",
+	"error.marshal_suit":       "Cannot convert suit to text: %d",
+	"error.parse_hand_type":    "Cannot parse hand type: %q",
+	"error.marshal_hand_type":  "Cannot convert hand type to text: %d",
+	"error.legacy_hand_type":   "Undefined legacy hand type: %d",
+	"error.deck_numbers":       "Invalid deck ranks: %v",
+	"error.deck_suits":         "Invalid deck suits: %v",
+	"error.deck_copies":        "Invalid number of decks: %d",
+	"error.paytable_read":      "Failed to read paytable %s: %v",
+	"error.paytable_format":    "Unsupported paytable format: %s",
+	"error.paytable_parse":     "Failed to parse paytable: %v",
+	"error.paytable_version":   "Paytable is missing a version",
+	"error.paytable_mode":      "Paytable %s has an invalid mode: %q",
+	"error.paytable_empty":     "Paytable %s has no hand types",
+	"error.paytable_hand_type": "Paytable %s has an undefined hand type: %d",
+	"error.paytable_negative":  "Paytable %s: the pay for %v cannot be negative: %d",
+	"error.hand_size":          "Invalid hand size: %d",
+	"error.select_disabled":    "Cannot choose the cards to settle now",
+	"error.select_count":       "Choose 5 cards, got %d",
+	"error.select_idx":         "Invalid hand positions: %v",
+	"error.empty_hand":         "The hand cannot be empty",
+	"error.hand_flag":          "Specify %d cards with -hand, got %d",
+	"error.no_player":          "The game has no player",
+	"error.set_hand":           "%s is not in the deck and cannot be put in the hand",
+	"error.not_enough_cards":   "Only %d cards left in the deck, cannot draw %d",
+	"error.discard_range":      "Discard position out of range: %d",
+	"error.discard_duplicate":  "Duplicate discard position: %d",
+	"error.dead_card":          "Not enough cards in the deck to remove: Idx %d",
+	"error.min_hand_size":      "Hand size must be at least 5, got %d",
+	"error.deck_too_small":     "The deck has only %d cards, cannot deal %d",
+	"error.strategy":           "Unknown strategy: %q",
+	"error.end_reason":         "Cannot convert end reason to text: %d",
+	"error.sessions":           "Number of sessions must be positive: %d",
+	"error.max_games":          "Max games per session must be positive: %d",
+	"error.no_strategy":        "Missing simulation strategy",
+	"error.rounds":             "Number of rounds must be positive: %d",
+	"error.rules":              "Unknown rules: %q",
+	"error.rng":                "Unknown random number algorithm: %q",
+	"error.simulation_rng":     "Simulations must be reproducible from the seed and cannot use the %q algorithm",
+	"error.index":              "Invalid index: %q",
+	"error.format":             "Unsupported output format: %q",
+
+	"cli.commands":      "============Commands============ \n1. reset(reset the game), \n2. play(settle and start a new game), \n3. d-0,2(replace the 1st and 3rd cards), \n4. s-0,1,2,3,4(choose the 5 cards to settle, requires -choose-hand), \n5. lang-zh-TW(switch language)",
+	"cli.prompt":        "Enter a command: ",
+	"cli.invalid_input": "Invalid input",
+	"cli.need_idx":      "Enter the indices of the cards to replace",
+	"cli.invalid_idx":   "Invalid index: %v",
+	"cli.reset":         "Game reset",
+	"cli.locale":        "Language switched to: %v",
+
+	"cli.usage":    "Usage: math-discard-card <command> [flags]\n\nCommands:\n  play      play interactively (default)\n  odds      hand type distribution after a discard\n  ev        expected value of every discard choice\n  rtp       theoretical return of the whole game\n  simulate  Monte Carlo simulation\n  chart     optimal hold strategy chart for every starting hand\n\nRun math-discard-card <command> -h for flags",
+	"cli.unknown":  "Unknown command: %v",
+	"cli.error":    "Error: %v",
+	"cli.progress": "Progress: %d/%d (%.1f%%)",
+
+	"odds.header": "Hand: %v  Discard positions: %v  Combinations: %d",
+	"odds.row":    "%v\t%d\t%v\t%v\t%v",
+
+	"ev.header": "Discard cost: %d",
+	"ev.row":    "%vDiscard positions: %v\tEV: %v\tNet EV: %v",
+	"ev.plan":   "Allowing up to %d discards: %v, expected net points %v",
+	"ev.settle": "settle",
+	"ev.draw":   "discard positions %v",
+
+	"rtp.strategy":   "Strategy: %v  Starting hands: %d",
+	"rtp.payout":     "Expected payout per game: %v  Expected cost per game: %v",
+	"rtp.rtp":        "RTP: %v  House edge: %v  Std dev: %.6f",
+	"rtp.hit":        "%v\t%v",
+	"rtp.hit_header": "Hit frequency:",
+
+	"simulate.header":     "Strategy: %v  Rounds: %d  Seed: %d",
+	"simulate.rtp":        "RTP: %v  95%% CI: [%.6f%%, %.6f%%]",
+	"simulate.metric":     "%v\tMean: %.6f\tStd dev: %.6f\t95%% CI: [%.6f, %.6f]\tPercentiles(1/5/25/50/75/95/99): %v",
+	"simulate.net":        "Net",
+	"simulate.payout":     "Payout",
+	"simulate.cost":       "Cost",
+	"simulate.discards":   "Discards",
+	"simulate.hit":        "%v\t%v\t95%% CI: [%.6f, %.6f]",
+	"simulate.hit_header": "Hit frequency:",
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math-discard-card/card"
+	"math-discard-card/game"
+	"math-discard-card/i18n"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 互動式遊玩的設定, reset 時會用同樣的設定重新開始
+var playOptions struct {
+	options
+	pt         int
+	chooseHand bool
+	rng        string
+	seed       int64
+	rules      *card.Rules
+	paytable   *card.Paytable
+}
+
+// 互動式遊玩目前的牌局
+var session *game.Session
+
+func runPlay(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	playOptions.register(fs, true)
+	fs.IntVar(&playOptions.pt, "pt", 100, "玩家起始點數")
+	fs.BoolVar(&playOptions.chooseHand, "choose-hand", false, "手牌超過5張時由玩家選擇結算的5張")
+	fs.StringVar(&playOptions.rng, "rng", "crypto", "洗牌用的亂數演算法(pcg、chacha8、crypto)")
+	fs.Int64Var(&playOptions.seed, "seed", 0, "pcg、chacha8 的亂數種子, 0 為目前時間; 相同種子可以重播同樣的牌局")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var err error
+	if playOptions.rules, err = playOptions.loadRules(); err != nil {
+		return err
+	}
+	if playOptions.paytable, err = playOptions.loadPaytable(); err != nil {
+		return err
+	}
+	locale, err := playOptions.loadLocale()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if err := resetGame(locale); err != nil {
+		return err
+	}
+	fmt.Println(msg("cli.commands"))
+	for {
+
+		fmt.Println()
+		fmt.Print(msg("cli.prompt"))
+
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		input = strings.TrimSpace(input)
+
+		parts := strings.SplitN(input, "-", 2)
+		if len(parts) < 1 {
+			fmt.Println(msg("cli.invalid_input"))
+			continue
+		}
+
+		switch parts[0] {
+		case "reset":
+			if err := resetGame(session.Player.Locale); err != nil {
+				return err
+			}
+		case "play":
+			if err := session.Settlement(); err != nil {
+				return err
+			}
+			if err := session.NewGame(); err != nil {
+				return err
+			}
+		case "d":
+			if len(parts) < 2 {
+				fmt.Println(msg("cli.need_idx"))
+				continue
+			}
+			idxStrs := strings.Split(parts[1], ",")
+			idxs := []int{}
+			for _, idxStr := range idxStrs {
+				idx, err := strconv.Atoi(idxStr)
+				if err != nil {
+					fmt.Println(msg("cli.invalid_idx", idxStr))
+					continue
+				}
+				idxs = append(idxs, idx)
+			}
+			if err := session.DiscardCard(idxs...); err != nil {
+				return err
+			}
+			session.ShowCards()
+		case "s":
+			if len(parts) < 2 {
+				fmt.Println(msg("cli.need_idx"))
+				continue
+			}
+			idxs, err := parseIdxs(parts[1])
+			if err != nil {
+				fmt.Println(errMsg(err))
+				continue
+			}
+			if err := session.SelectCards(idxs...); err != nil {
+				fmt.Println(errMsg(err))
+				continue
+			}
+			session.ShowCards()
+		case "lang":
+			if len(parts) < 2 {
+				fmt.Println(msg("cli.invalid_input"))
+				continue
+			}
+			locale, err := i18n.ParseLocale(parts[1])
+			if err != nil {
+				fmt.Println(msg("cli.invalid_input"))
+				continue
+			}
+			session.Player.Locale = locale
+			fmt.Println(msg("cli.locale", locale))
+		default:
+			fmt.Println(msg("cli.invalid_input"))
+		}
+	}
+}
+
+func resetGame(locale i18n.Locale) error {
+	engine, err := game.NewEngine(game.Config{
+		GameCost:           playOptions.gameCost,
+		DefaultDiscardCost: playOptions.discardCost,
+		DiscardAddCost:     playOptions.discardAdd,
+		HandSize:           playOptions.handSize,
+		ChooseHand:         playOptions.chooseHand,
+		Rules:              playOptions.rules,
+		Paytable:           playOptions.paytable,
+		Locale:             locale,
+		RNG:                playOptions.rng,
+	})
+	if err != nil {
+		return err
+	}
+	seed := playOptions.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	session = engine.NewSession(playOptions.pt, seed)
+	fmt.Println(msg("cli.reset"))
+	if err := session.NewGame(); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+// 依玩家語系取得指令列訊息
+func msg(key string, args ...any) string {
+	return i18n.T(playLocale(), key, args...)
+}
+
+// 依玩家語系顯示錯誤
+func errMsg(err error) string {
+	return msg("cli.error", i18n.Message(playLocale(), err))
+}
+
+func playLocale() i18n.Locale {
+	if session == nil {
+		return cliLocale
+	}
+	return session.Player.Locale
+}
@@ -0,0 +1,188 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"math-discard-card/analysis"
+	"math-discard-card/card"
+	"math-discard-card/game"
+	"math-discard-card/i18n"
+)
+
+// SessionEnd 一次遊玩結束的原因
+type SessionEnd int
+
+const (
+	Ruin     SessionEnd = iota // 點數不夠開下一局
+	StopWin                    // 贏到停利點
+	StopLoss                   // 輸到停損點
+	MaxGames                   // 玩到局數上限
+)
+
+var sessionEndNames = [...]string{Ruin: "ruin", StopWin: "stop_win", StopLoss: "stop_loss", MaxGames: "max_games"}
+
+// MarshalText 實作 encoding.TextMarshaler, 輸出 "ruin" 等英文名稱
+func (e SessionEnd) MarshalText() ([]byte, error) {
+	if e < Ruin || e > MaxGames {
+		return nil, i18n.Errorf("error.end_reason", int(e))
+	}
+	return []byte(sessionEndNames[e]), nil
+}
+
+// SessionConfig 玩家遊玩模擬的設定
+type SessionConfig struct {
+	Sessions     int                 // 模擬幾次遊玩
+	Seed         int64               // 亂數種子, 相同種子與設定會得到相同結果
+	RNG          string              // 洗牌用的亂數演算法(見 RNGNames), 空白為 pcg; 不接受無法以種子重現的 crypto
+	StartBalance int                 // 每次遊玩的起始點數
+	StopWin      int                 // 贏到這麼多點時停止, 0 表示不設定
+	StopLoss     int                 // 輸到這麼多點時停止, 0 表示不設定
+	MaxGames     int                 // 每次遊玩最多玩幾局
+	Game         analysis.GameConfig // 開局與換牌花費、換牌次數上限
+	Rules        *card.Rules         // 玩法規則, nil 為標準規則
+	Paytable     *card.Paytable      // 賠率表, nil 為預設賠率表
+	Strategy     analysis.Strategy   // 每局換牌的策略, Engine 有多個 worker 時必須可以同時在多個 goroutine 中使用
+	Engine       *analysis.Engine    // 平行模擬的設定, nil 時依序模擬
+}
+
+// SessionReport 玩家遊玩模擬結果
+type SessionReport struct {
+	Sessions     int                    `json:"sessions"`
+	Seed         int64                  `json:"seed"`
+	Strategy     string                 `json:"strategy"`
+	Ends         map[SessionEnd]float64 `json:"ends"`          // 各結束原因的機率
+	RuinCI95     [2]float64             `json:"ruin_ci95"`     // 破產機率的95%信賴區間
+	Length       Summary                `json:"length"`        // 每次遊玩的局數
+	FinalBalance Summary                `json:"final_balance"` // 結束時的點數
+	RuinByGame   []float64              `json:"ruin_by_game"`  // RuinByGame[i] 為第 i+1 局以內破產的累積機率
+}
+
+// 一段遊玩模擬的統計
+type sessionStats struct {
+	length, balance *histogram
+	ends            [MaxGames + 1]int64
+	ruinAt          []int64
+}
+
+func newSessionStats(maxGames int) *sessionStats {
+	return &sessionStats{length: newHistogram(), balance: newHistogram(), ruinAt: make([]int64, maxGames)}
+}
+
+func (s *sessionStats) merge(other *sessionStats) {
+	s.length.merge(other.length)
+	s.balance.merge(other.balance)
+	for end, count := range other.ends {
+		s.ends[end] += count
+	}
+	for i, count := range other.ruinAt {
+		s.ruinAt[i] += count
+	}
+}
+
+// 每段模擬幾次遊玩
+const sessionChunkSize = 64
+
+// RunSessions 模擬 config.Sessions 次玩家以起始點數遊玩, 直到破產、停利、停損或玩到局數上限
+//
+// 每次遊玩是獨立的 game.Session, 以種子與遊玩編號推得的亂數洗牌, 交給 config.Engine 平行模擬;
+// 相同種子與設定會得到相同結果, 與 worker 數量無關
+func RunSessions(config SessionConfig) (*SessionReport, error) {
+	return RunSessionsContext(context.Background(), config)
+}
+
+// RunSessionsContext 同 RunSessions, 可以用 ctx 取消
+func RunSessionsContext(ctx context.Context, config SessionConfig) (*SessionReport, error) {
+	if config.Sessions <= 0 {
+		return nil, i18n.Errorf("error.sessions", config.Sessions)
+	}
+	if config.MaxGames <= 0 {
+		return nil, i18n.Errorf("error.max_games", config.MaxGames)
+	}
+	if config.Strategy == nil {
+		return nil, i18n.Errorf("error.no_strategy")
+	}
+	engine, err := newEngine(config.Game, config.Rules, config.Paytable, config.RNG)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newSessionStats(config.MaxGames)
+	err = analysis.RunParallel(ctx, config.Engine, int64(config.Sessions), sessionChunkSize, func(ctx context.Context, start, end int64) (*sessionStats, error) {
+		partial := newSessionStats(config.MaxGames)
+		for i := start; i < end; i++ {
+			session := engine.NewSession(config.StartBalance, chunkSeed(config.Seed, i))
+			games, stop, err := playSession(ctx, session, config)
+			if err != nil {
+				return nil, err
+			}
+			if stop == Ruin {
+				partial.ruinAt[max(games-1, 0)]++
+			}
+			partial.ends[stop]++
+			partial.length.add(games)
+			partial.balance.add(session.Player.Pt)
+		}
+		return partial, nil
+	}, stats.merge)
+	if err != nil {
+		return nil, err
+	}
+	length, balance, ends, ruinAt := stats.length, stats.balance, stats.ends, stats.ruinAt
+
+	n := float64(config.Sessions)
+	report := &SessionReport{
+		Sessions:     config.Sessions,
+		Seed:         config.Seed,
+		Strategy:     config.Strategy.Name(),
+		Ends:         make(map[SessionEnd]float64, 4),
+		Length:       length.summary(),
+		FinalBalance: balance.summary(),
+		RuinByGame:   make([]float64, config.MaxGames),
+	}
+	for _, end := range []SessionEnd{Ruin, StopWin, StopLoss, MaxGames} {
+		report.Ends[end] = float64(ends[end]) / n
+	}
+	ruin := report.Ends[Ruin]
+	margin := z95 * math.Sqrt(ruin*(1-ruin)/n)
+	report.RuinCI95 = [2]float64{math.Max(ruin-margin, 0), math.Min(ruin+margin, 1)}
+	var cumulative int64
+	for i, count := range ruinAt {
+		cumulative += count
+		report.RuinByGame[i] = float64(cumulative) / n
+	}
+	return report, nil
+}
+
+// 玩家從起始點數一直玩到要停止, 回傳玩了幾局與結束原因
+func playSession(ctx context.Context, session *game.Session, config SessionConfig) (int, SessionEnd, error) {
+	games := 0
+	for games < config.MaxGames {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		if session.Player.Pt < config.Game.GameCost {
+			return games, Ruin, nil
+		}
+		if _, _, err := playRound(session, config.Game, config.Strategy); err != nil {
+			return 0, 0, err
+		}
+		games++
+		if stop, ok := sessionStop(config, session.Player.Pt); ok {
+			return games, stop, nil
+		}
+	}
+	return games, MaxGames, nil
+}
+
+// 一局結束後是否要停止遊玩
+func sessionStop(config SessionConfig, pt int) (SessionEnd, bool) {
+	switch {
+	case pt < config.Game.GameCost:
+		return Ruin, true
+	case config.StopWin > 0 && pt >= config.StartBalance+config.StopWin:
+		return StopWin, true
+	case config.StopLoss > 0 && pt <= config.StartBalance-config.StopLoss:
+		return StopLoss, true
+	}
+	return 0, false
+}
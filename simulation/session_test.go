@@ -0,0 +1,101 @@
+package simulation
+
+import (
+	"math-discard-card/analysis"
+	"math-discard-card/card"
+	"reflect"
+	"testing"
+)
+
+// 2~4 梅花、紅心、黑桃共9張, 每手牌至少兩對, 從不換牌時每局輸贏固定
+func fixedSessionConfig(pay int) SessionConfig {
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: pay, card.ThreeOfAKind: pay, card.FullHouse: pay}}
+	return SessionConfig{
+		Sessions:     10,
+		Seed:         1,
+		StartBalance: 10,
+		MaxGames:     100,
+		Game:         analysis.GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1},
+		Rules:        rules,
+		Paytable:     paytable,
+		Strategy:     analysis.NeverDiscard{},
+	}
+}
+
+func TestRunSessions(t *testing.T) {
+	tests := []struct {
+		name    string
+		pay     int
+		update  func(config *SessionConfig)
+		end     SessionEnd
+		games   float64
+		balance float64
+	}{
+		{"Ruin", 0, func(config *SessionConfig) {}, Ruin, 5, 0},
+		{"StopWin", 3, func(config *SessionConfig) { config.StopWin = 5 }, StopWin, 5, 15},
+		{"StopLoss", 1, func(config *SessionConfig) { config.StopLoss = 3 }, StopLoss, 3, 7},
+		{"MaxGames", 2, func(config *SessionConfig) { config.MaxGames = 4 }, MaxGames, 4, 10},
+	}
+
+	for _, tt := range tests {
+		config := fixedSessionConfig(tt.pay)
+		tt.update(&config)
+		report, err := RunSessions(config)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if report.Ends[tt.end] != 1 {
+			t.Errorf("%s: expected all sessions to end with %d, got %v", tt.name, tt.end, report.Ends)
+		}
+		if report.Length.Mean != tt.games || report.FinalBalance.Mean != tt.balance {
+			t.Errorf("%s: expected %v games and balance %v, got %v and %v", tt.name, tt.games, tt.balance, report.Length.Mean, report.FinalBalance.Mean)
+		}
+		if len(report.RuinByGame) != config.MaxGames {
+			t.Errorf("%s: expected ruin curve of %d games, got %d", tt.name, config.MaxGames, len(report.RuinByGame))
+		}
+	}
+
+	report, err := RunSessions(fixedSessionConfig(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.RuinByGame[3] != 0 || report.RuinByGame[4] != 1 || report.RuinByGame[99] != 1 {
+		t.Errorf("expected ruin at the 5th game, got %v", report.RuinByGame[:6])
+	}
+}
+
+func TestRunSessionsReproducible(t *testing.T) {
+	config := smallConfig(0, 7)
+	sessionConfig := SessionConfig{
+		Sessions:     50,
+		Seed:         7,
+		StartBalance: 20,
+		StopWin:      20,
+		MaxGames:     50,
+		Game:         config.Game,
+		Rules:        config.Rules,
+		Paytable:     config.Paytable,
+		Strategy:     config.Strategy,
+	}
+	first, err := RunSessions(sessionConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := RunSessions(sessionConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected same report for same seed")
+	}
+	// 每次遊玩的亂數只跟編號有關, 分段與 worker 數量不影響結果
+	sessionConfig.Engine = &analysis.Engine{Workers: 4, ChunkSize: 3}
+	parallel, err := RunSessions(sessionConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first, parallel) {
+		t.Errorf("expected same report when running in parallel")
+	}
+}
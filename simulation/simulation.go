@@ -0,0 +1,321 @@
+package simulation
+
+import (
+	"context"
+	"io"
+	"math"
+	"math-discard-card/analysis"
+	"math-discard-card/card"
+	"math-discard-card/game"
+	"math-discard-card/i18n"
+	"math-discard-card/utility"
+	"math/big"
+	"sort"
+)
+
+// Config 模擬設定
+type Config struct {
+	Rounds   int                 // 模擬局數
+	Seed     int64               // 亂數種子, 相同種子與設定會得到相同結果
+	RNG      string              // 洗牌用的亂數演算法(見 RNGNames), 空白為 pcg; 不接受無法以種子重現的 crypto
+	Game     analysis.GameConfig // 開局與換牌花費、換牌次數上限
+	Rules    *card.Rules         // 玩法規則, nil 為標準規則
+	Paytable *card.Paytable      // 賠率表, nil 為預設賠率表
+	Strategy analysis.Strategy   // 每局換牌的策略, Engine 有多個 worker 時必須可以同時在多個 goroutine 中使用
+	Engine   *analysis.Engine    // 平行模擬的設定, nil 時依序模擬
+}
+
+// Percentiles 報表中列出的百分位數
+var Percentiles = []int{1, 5, 25, 50, 75, 95, 99}
+
+// Summary 某個指標每局數值的統計
+type Summary struct {
+	Mean        float64         `json:"mean"`
+	ExactMean   *big.Rat        `json:"exact_mean"` // 平均值的精確值, 各局數值都是整數
+	Variance    float64         `json:"variance"`
+	StdDev      float64         `json:"std_dev"`
+	CI95        [2]float64      `json:"ci95"`        // 平均值的95%信賴區間
+	Percentiles map[int]float64 `json:"percentiles"` // 百分位數, 例如 Percentiles[50] 為中位數
+}
+
+// Report 模擬結果
+type Report struct {
+	Rounds       int                          `json:"rounds"`
+	Seed         int64                        `json:"seed"`
+	Strategy     string                       `json:"strategy"`
+	RTP          float64                      `json:"rtp"`           // 總拿回點數 / 總花費
+	RTPCI95      [2]float64                   `json:"rtp_ci95"`      // RTP 的95%信賴區間
+	Net          Summary                      `json:"net"`           // 每局淨輸贏點數
+	Payout       Summary                      `json:"payout"`        // 每局拿回的點數
+	Cost         Summary                      `json:"cost"`          // 每局花費(開局 + 換牌)
+	Discards     Summary                      `json:"discards"`      // 每局換牌次數
+	HitFrequency map[card.HandType]float64    `json:"hit_frequency"` // 結算時各牌型的機率
+	HitCI95      map[card.HandType][2]float64 `json:"hit_ci95"`      // 各牌型機率的95%信賴區間
+
+	ExactRTP          *big.Rat                   `json:"exact_rtp"`           // RTP 的精確值, 總花費為0時為 nil
+	ExactHitFrequency map[card.HandType]*big.Rat `json:"exact_hit_frequency"` // 各牌型機率的精確值, 加總為1
+}
+
+// 95%信賴區間的常態分布臨界值
+const z95 = 1.959963984540054
+
+// 整數指標的計數, 百分位數直接從分布取得, 不需要保留每局數值
+type histogram struct {
+	counts map[int]int64
+	sum    int64
+	sumSq  float64
+	n      int64
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make(map[int]int64)}
+}
+
+func (h *histogram) add(value int) {
+	h.counts[value]++
+	h.sum += int64(value)
+	h.sumSq += float64(value) * float64(value)
+	h.n++
+}
+
+// 合併另一段模擬的計數
+func (h *histogram) merge(other *histogram) {
+	for value, count := range other.counts {
+		h.counts[value] += count
+	}
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+	h.n += other.n
+}
+
+func (h *histogram) mean() float64 {
+	return float64(h.sum) / float64(h.n)
+}
+
+func (h *histogram) exactMean() *big.Rat {
+	return big.NewRat(h.sum, h.n)
+}
+
+// 樣本變異數
+func (h *histogram) variance() float64 {
+	if h.n < 2 {
+		return 0
+	}
+	mean := h.mean()
+	return math.Max(h.sumSq-float64(h.n)*mean*mean, 0) / float64(h.n-1)
+}
+
+func (h *histogram) summary() Summary {
+	mean := h.mean()
+	variance := h.variance()
+	margin := z95 * math.Sqrt(variance/float64(h.n))
+	summary := Summary{
+		Mean:        mean,
+		ExactMean:   h.exactMean(),
+		Variance:    variance,
+		StdDev:      math.Sqrt(variance),
+		CI95:        [2]float64{mean - margin, mean + margin},
+		Percentiles: make(map[int]float64, len(Percentiles)),
+	}
+
+	values := make([]int, 0, len(h.counts))
+	for value := range h.counts {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+	for _, p := range Percentiles {
+		// 最近排名法: 第 ceil(p% * n) 小的數值
+		rank := int64(math.Ceil(float64(p) / 100 * float64(h.n)))
+		if rank < 1 {
+			rank = 1
+		}
+		var seen int64
+		for _, value := range values {
+			seen += h.counts[value]
+			if seen >= rank {
+				summary.Percentiles[p] = float64(value)
+				break
+			}
+		}
+	}
+	return summary
+}
+
+// 一段模擬局數的統計
+type roundStats struct {
+	net, payout, cost, discards *histogram
+	hits                        [card.RoyalFlush + 1]int64
+}
+
+func newRoundStats() *roundStats {
+	return &roundStats{net: newHistogram(), payout: newHistogram(), cost: newHistogram(), discards: newHistogram()}
+}
+
+func (s *roundStats) merge(other *roundStats) {
+	s.net.merge(other.net)
+	s.payout.merge(other.payout)
+	s.cost.merge(other.cost)
+	s.discards.merge(other.discards)
+	for handType, hit := range other.hits {
+		s.hits[handType] += hit
+	}
+}
+
+// 每段模擬的局數, 每段使用由種子與段落起點推得的獨立亂數
+const roundChunkSize = 1 << 10
+
+// Run 以固定種子模擬 config.Rounds 局, 每局依策略換牌後結算
+//
+// 局數會分段交給 config.Engine 平行模擬, 每段是獨立的 game.Session;
+// 相同的種子、設定與 Engine.ChunkSize 會得到相同結果, 與 worker 數量無關
+func Run(config Config) (*Report, error) {
+	return RunContext(context.Background(), config)
+}
+
+// RunContext 同 Run, 可以用 ctx 取消
+func RunContext(ctx context.Context, config Config) (*Report, error) {
+	if config.Rounds <= 0 {
+		return nil, i18n.Errorf("error.rounds", config.Rounds)
+	}
+	if config.Strategy == nil {
+		return nil, i18n.Errorf("error.no_strategy")
+	}
+	engine, err := newEngine(config.Game, config.Rules, config.Paytable, config.RNG)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newRoundStats()
+	err = analysis.RunParallel(ctx, config.Engine, int64(config.Rounds), roundChunkSize, func(ctx context.Context, start, end int64) (*roundStats, error) {
+		// 點數給到足夠大, 模擬時不會因為點數不足而不能換牌
+		session := engine.NewSession(math.MaxInt32, chunkSeed(config.Seed, start))
+		partial := newRoundStats()
+		for round := start; round < end; round++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			before := session.Player.Pt
+			handType, beforeSettlement, err := playRound(session, config.Game, config.Strategy)
+			if err != nil {
+				return nil, err
+			}
+			after := session.Player.Pt
+
+			partial.net.add(after - before)
+			partial.payout.add(after - beforeSettlement)
+			partial.cost.add(before - beforeSettlement)
+			partial.discards.add(session.CurDiscardCount)
+			partial.hits[handType]++
+			session.Player.Pt = before
+		}
+		return partial, nil
+	}, stats.merge)
+	if err != nil {
+		return nil, err
+	}
+	net, payout, cost, discards := stats.net, stats.payout, stats.cost, stats.discards
+
+	report := &Report{
+		Rounds:       config.Rounds,
+		Seed:         config.Seed,
+		Strategy:     config.Strategy.Name(),
+		Net:          net.summary(),
+		Payout:       payout.summary(),
+		Cost:         cost.summary(),
+		Discards:     discards.summary(),
+		HitFrequency: make(map[card.HandType]float64, len(card.AllHandTypes)),
+		HitCI95:      make(map[card.HandType][2]float64, len(card.AllHandTypes)),
+
+		ExactHitFrequency: make(map[card.HandType]*big.Rat, len(card.AllHandTypes)),
+	}
+	n := float64(config.Rounds)
+	for _, handType := range card.AllHandTypes {
+		report.ExactHitFrequency[handType] = big.NewRat(stats.hits[handType], int64(config.Rounds))
+		p := float64(stats.hits[handType]) / n
+		margin := z95 * math.Sqrt(p*(1-p)/n)
+		report.HitFrequency[handType] = p
+		report.HitCI95[handType] = [2]float64{math.Max(p-margin, 0), math.Min(p+margin, 1)}
+	}
+	report.RTP, report.RTPCI95 = ratioCI(payout, cost, net)
+	if cost.sum != 0 {
+		report.ExactRTP = big.NewRat(payout.sum, cost.sum)
+	}
+	return report, nil
+}
+
+// RNGNames 模擬可以使用的亂數演算法, 都能以種子重現
+var RNGNames = []string{"pcg", "chacha8"}
+
+// 建立不輸出訊息的遊戲引擎, 每段模擬各自建立 Session
+//
+// 模擬結果要能以種子重現, 不使用種子的 crypto 會回傳錯誤
+func newEngine(config analysis.GameConfig, rules *card.Rules, paytable *card.Paytable, rng string) (*game.Engine, error) {
+	if rng == "crypto" {
+		return nil, i18n.Errorf("error.simulation_rng", rng)
+	}
+	engine, err := game.NewEngine(game.Config{
+		GameCost:           config.GameCost,
+		DefaultDiscardCost: config.DefaultDiscardCost,
+		DiscardAddCost:     config.DiscardAddCost,
+		HandSize:           config.HandSize,
+		Rules:              rules,
+		Paytable:           paytable,
+		RNG:                rng,
+	})
+	if err != nil {
+		return nil, err
+	}
+	engine.Output = io.Discard
+	return engine, nil
+}
+
+// 由設定的種子與段落起點以 splitmix64 推得該段使用的種子
+func chunkSeed(seed, start int64) int64 {
+	return int64(utility.SplitMix64(uint64(seed) + uint64(start)*0x9e3779b97f4a7c15))
+}
+
+// 玩一局: 開局後依策略換牌直到結算, 點數不夠換牌時直接結算; 回傳結算牌型與結算前的點數
+func playRound(g *game.Session, config analysis.GameConfig, strategy analysis.Strategy) (card.HandType, int, error) {
+	if err := g.NewGame(); err != nil {
+		return 0, 0, err
+	}
+	for config.MaxRounds <= 0 || g.CurDiscardCount < config.MaxRounds {
+		if g.Player.Pt < config.DiscardCost().At(g.CurDiscardCount) {
+			break
+		}
+		decision, err := strategy.Decide(g.AnalysisState())
+		if err != nil {
+			return 0, 0, err
+		}
+		if decision.Settle || len(decision.Discard) == 0 {
+			break
+		}
+		if err := g.DiscardCard(decision.Discard...); err != nil {
+			return 0, 0, err
+		}
+	}
+	beforeSettlement := g.Player.Pt
+	handType := g.GetHandType()
+	if err := g.Settlement(); err != nil {
+		return 0, 0, err
+	}
+	return handType, beforeSettlement, nil
+}
+
+// 比例估計 sum(payout)/sum(cost) 的信賴區間, 以 delta method 近似
+//
+// 變異數由 payout - RTP*cost 推得, 其中 payout - cost 就是淨輸贏
+func ratioCI(payout, cost, net *histogram) (float64, [2]float64) {
+	meanCost := cost.mean()
+	if meanCost == 0 {
+		return 0, [2]float64{}
+	}
+	rtp := float64(payout.sum) / float64(cost.sum)
+	// Var(payout - rtp*cost) = Var(payout) + rtp²Var(cost) - 2rtp*Cov(payout, cost)
+	// Cov(payout, cost) = (Var(payout) + Var(cost) - Var(net)) / 2
+	varPayout, varCost, varNet := payout.variance(), cost.variance(), net.variance()
+	covariance := (varPayout + varCost - varNet) / 2
+	variance := math.Max(varPayout+rtp*rtp*varCost-2*rtp*covariance, 0)
+	margin := z95 * math.Sqrt(variance/float64(payout.n)) / meanCost
+	return rtp, [2]float64{rtp - margin, rtp + margin}
+}
@@ -0,0 +1,132 @@
+package simulation
+
+import (
+	"math"
+	"math-discard-card/analysis"
+	"math-discard-card/card"
+	"math-discard-card/i18n"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// 2~4 梅花、紅心、黑桃共9張, 兩對1、葫蘆10, 換1次的理論 RTP 為 2.4875
+func smallConfig(rounds int, seed int64) Config {
+	rules := &card.Rules{Deck: card.DeckSpec{Numbers: []int{2, 3, 4}, Suits: []card.SuitType{card.Clubs, card.Hearts, card.Spades}}}
+	paytable := &card.Paytable{Version: "test", Mode: card.PayFor, Pays: map[card.HandType]int{card.TwoPair: 1, card.FullHouse: 10}}
+	gameConfig := analysis.GameConfig{GameCost: 2, DefaultDiscardCost: 1, DiscardAddCost: 1, MaxRounds: 1}
+	strategy, _ := analysis.NewAnalyzer(rules, paytable).NewStrategy("optimal", gameConfig)
+	return Config{
+		Rounds:   rounds,
+		Seed:     seed,
+		Game:     gameConfig,
+		Rules:    rules,
+		Paytable: paytable,
+		Strategy: strategy,
+	}
+}
+
+func TestRun(t *testing.T) {
+	report, err := Run(smallConfig(5000, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.RTPCI95[0] > 2.4875 || report.RTPCI95[1] < 2.4875 {
+		t.Errorf("expected RTP CI %v to contain 2.4875 (RTP %f)", report.RTPCI95, report.RTP)
+	}
+	if math.Abs(report.Net.Mean-(report.Payout.Mean-report.Cost.Mean)) > 1e-9 {
+		t.Errorf("expected net mean %f = payout %f - cost %f", report.Net.Mean, report.Payout.Mean, report.Cost.Mean)
+	}
+	if report.Discards.Percentiles[99] > 1 {
+		t.Errorf("expected at most 1 discard, got %v", report.Discards.Percentiles)
+	}
+	if hit := report.HitFrequency[card.FullHouse]; report.HitCI95[card.FullHouse][0] > 0.6785714285714285 || report.HitCI95[card.FullHouse][1] < 0.6785714285714285 {
+		t.Errorf("expected full house CI %v to contain 0.6786 (hit %f)", report.HitCI95[card.FullHouse], hit)
+	}
+
+	// 精確值: 淨點數平均 = 拿回 - 花費, 各牌型機率加總為1
+	if net := new(big.Rat).Sub(report.Payout.ExactMean, report.Cost.ExactMean); net.Cmp(report.Net.ExactMean) != 0 {
+		t.Errorf("expected exact net mean %s, got %s", net.RatString(), report.Net.ExactMean.RatString())
+	}
+	if rtp, _ := report.ExactRTP.Float64(); math.Abs(rtp-report.RTP) > 1e-12 {
+		t.Errorf("expected exact RTP %s to match %f", report.ExactRTP.RatString(), report.RTP)
+	}
+	sum := new(big.Rat)
+	for _, hit := range report.ExactHitFrequency {
+		sum.Add(sum, hit)
+	}
+	if sum.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("expected exact hit frequencies to sum to 1, got %s", sum.RatString())
+	}
+}
+
+func TestRunReproducible(t *testing.T) {
+	first, err := Run(smallConfig(1000, 42))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := Run(smallConfig(1000, 42))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected same report for same seed")
+	}
+	other, err := Run(smallConfig(1000, 43))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reflect.DeepEqual(first.Net, other.Net) {
+		t.Errorf("expected different report for different seed")
+	}
+}
+
+func TestRunRejectsCrypto(t *testing.T) {
+	// crypto 不使用種子, 模擬結果無法重現
+	config := smallConfig(10, 1)
+	config.RNG = "crypto"
+	if _, err := Run(config); !isErrorKey(err, "error.simulation_rng") {
+		t.Errorf("expected Run to reject crypto, got %v", err)
+	}
+	sessionConfig := SessionConfig{Sessions: 1, Seed: 1, RNG: "crypto", StartBalance: 10, MaxGames: 1,
+		Game: config.Game, Rules: config.Rules, Paytable: config.Paytable, Strategy: config.Strategy}
+	if _, err := RunSessions(sessionConfig); !isErrorKey(err, "error.simulation_rng") {
+		t.Errorf("expected RunSessions to reject crypto, got %v", err)
+	}
+}
+
+func isErrorKey(err error, key string) bool {
+	e, ok := err.(*i18n.Error)
+	return ok && e.Key == key
+}
+
+func TestRunWorkers(t *testing.T) {
+	// 分段方式相同時, 結果與 worker 數量無關
+	var reports []*Report
+	for _, workers := range []int{1, 4} {
+		config := smallConfig(3000, 5)
+		config.Engine = &analysis.Engine{Workers: workers, ChunkSize: 100}
+		report, err := Run(config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if !reflect.DeepEqual(reports[0], reports[1]) {
+		t.Errorf("expected same report for different worker counts")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	h := newHistogram()
+	for value := 1; value <= 100; value++ {
+		h.add(value)
+	}
+	summary := h.summary()
+	if summary.Mean != 50.5 || summary.Percentiles[50] != 50 || summary.Percentiles[99] != 99 || summary.Percentiles[1] != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if math.Abs(summary.Variance-841.6666666666666) > 1e-9 {
+		t.Errorf("expected variance 841.67, got %f", summary.Variance)
+	}
+}
@@ -0,0 +1,173 @@
+package utility
+
+import "fmt"
+
+// Combinations 從 items 中取 k 個的所有組合, 依索引的字典序逐一產生, 不會一次把所有組合放進記憶體
+//
+// 可以用 Next/Value 拉取, 也可以用 Each 走訪; 以 SeekRank 或 EachRange 依排名切成多段, 分給不同的 goroutine 處理
+type Combinations[T any] struct {
+	items []T
+	k     int
+	idxs  []int
+	value []T
+	rank  int64 // 下一次 Next 產生的組合排名
+	count int64
+}
+
+// NewCombinations 建立從 items 中取 k 個的組合迭代器, k 超出範圍時沒有任何組合
+func NewCombinations[T any](items []T, k int) *Combinations[T] {
+	c := &Combinations[T]{
+		items: items,
+		k:     k,
+		count: Binomial(len(items), k),
+	}
+	if c.count > 0 {
+		c.idxs = make([]int, k)
+		c.value = make([]T, k)
+	}
+	return c
+}
+
+// Binomial 組合數 C(n, k), 超出範圍時為0
+func Binomial(n, k int) int64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	if k > n-k {
+		k = n - k
+	}
+	result := int64(1)
+	for i := 1; i <= k; i++ {
+		result = result * int64(n-k+i) / int64(i)
+	}
+	return result
+}
+
+// Count 組合總數
+func (c *Combinations[T]) Count() int64 {
+	return c.count
+}
+
+// Next 移到下一個組合, 沒有更多組合時回傳 false
+func (c *Combinations[T]) Next() bool {
+	if c.rank >= c.count {
+		return false
+	}
+	if c.rank == 0 {
+		for i := range c.idxs {
+			c.idxs[i] = i
+		}
+	} else {
+		i := c.k - 1
+		n := len(c.items)
+		for c.idxs[i] == n-c.k+i {
+			i--
+		}
+		c.idxs[i]++
+		for j := i + 1; j < c.k; j++ {
+			c.idxs[j] = c.idxs[j-1] + 1
+		}
+	}
+	for i, idx := range c.idxs {
+		c.value[i] = c.items[idx]
+	}
+	c.rank++
+	return true
+}
+
+// Value 目前的組合, 回傳的切片在下一次 Next 時會被覆寫
+func (c *Combinations[T]) Value() []T {
+	return c.value
+}
+
+// Indices 目前組合在 items 中的索引, 由小到大, 下一次 Next 時會被覆寫
+func (c *Combinations[T]) Indices() []int {
+	return c.idxs
+}
+
+// Rank 目前組合的排名(從0開始)
+func (c *Combinations[T]) Rank() int64 {
+	return c.rank - 1
+}
+
+// SeekRank 讓下一次 Next 產生排名為 rank 的組合
+func (c *Combinations[T]) SeekRank(rank int64) error {
+	if rank < 0 || rank > c.count {
+		return fmt.Errorf("組合排名超出範圍: %d", rank)
+	}
+	if rank > 0 {
+		// 還原成前一個組合, Next 會從它往下走
+		idxs, err := CombinationUnrank(rank-1, len(c.items), c.k)
+		if err != nil {
+			return err
+		}
+		copy(c.idxs, idxs)
+	}
+	c.rank = rank
+	return nil
+}
+
+// Each 依序走訪所有剩下的組合, fn 回傳 false 時停止
+func (c *Combinations[T]) Each(fn func(combo []T) bool) {
+	for c.Next() {
+		if !fn(c.value) {
+			return
+		}
+	}
+}
+
+// EachRange 走訪排名在 [start, end) 之間的組合, fn 回傳 false 時停止
+func (c *Combinations[T]) EachRange(start, end int64, fn func(combo []T) bool) error {
+	if end > c.count {
+		end = c.count
+	}
+	if err := c.SeekRank(start); err != nil {
+		return err
+	}
+	for c.rank < end && c.Next() {
+		if !fn(c.value) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// CombinationRank 組合(由小到大的索引)在從 n 個取 len(idxs) 個的所有組合中的字典序排名
+func CombinationRank(idxs []int, n int) (int64, error) {
+	k := len(idxs)
+	var rank int64
+	prev := -1
+	for i, idx := range idxs {
+		if idx <= prev || idx >= n {
+			return 0, fmt.Errorf("組合索引必須由小到大且小於 %d: %v", n, idxs)
+		}
+		for j := prev + 1; j < idx; j++ {
+			rank += Binomial(n-1-j, k-1-i)
+		}
+		prev = idx
+	}
+	return rank, nil
+}
+
+// CombinationUnrank 從 n 個取 k 個的所有組合中, 字典序排名為 rank 的組合索引
+func CombinationUnrank(rank int64, n, k int) ([]int, error) {
+	if rank < 0 || rank >= Binomial(n, k) {
+		return nil, fmt.Errorf("組合排名超出範圍: %d", rank)
+	}
+	idxs := make([]int, k)
+	next := 0
+	for i := 0; i < k; i++ {
+		for {
+			// 以 next 開頭的組合數
+			count := Binomial(n-1-next, k-1-i)
+			if rank < count {
+				break
+			}
+			rank -= count
+			next++
+		}
+		idxs[i] = next
+		next++
+	}
+	return idxs, nil
+}
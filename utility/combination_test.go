@@ -0,0 +1,109 @@
+package utility
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCombinations(t *testing.T) {
+	tests := []struct {
+		items    []string
+		k        int
+		expected [][]string
+	}{
+		{[]string{"a", "b", "c", "d"}, 2, [][]string{{"a", "b"}, {"a", "c"}, {"a", "d"}, {"b", "c"}, {"b", "d"}, {"c", "d"}}},
+		{[]string{"a", "b", "c"}, 3, [][]string{{"a", "b", "c"}}},
+		{[]string{"a", "b"}, 0, [][]string{{}}},
+		{[]string{"a", "b"}, 3, nil},
+	}
+
+	for _, tt := range tests {
+		var result [][]string
+		combinations := NewCombinations(tt.items, tt.k)
+		for combinations.Next() {
+			result = append(result, append([]string{}, combinations.Value()...))
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("NewCombinations(%v, %d) = %v; expected %v", tt.items, tt.k, result, tt.expected)
+		}
+		if combinations.Count() != int64(len(tt.expected)) {
+			t.Errorf("Count() = %d; expected %d", combinations.Count(), len(tt.expected))
+		}
+	}
+}
+
+func TestCombinationsEach(t *testing.T) {
+	// 提前結束
+	visited := 0
+	NewCombinations([]int{1, 2, 3, 4, 5}, 3).Each(func(combo []int) bool {
+		visited++
+		return visited < 4
+	})
+	if visited != 4 {
+		t.Errorf("Expected to stop after 4 combinations, got %d", visited)
+	}
+
+	// 切成多段走訪後合起來要跟完整走訪相同
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	var all, ranged [][]int
+	NewCombinations(items, 4).Each(func(combo []int) bool {
+		all = append(all, append([]int{}, combo...))
+		return true
+	})
+	for start := int64(0); start < 126; start += 40 {
+		err := NewCombinations(items, 4).EachRange(start, start+40, func(combo []int) bool {
+			ranged = append(ranged, append([]int{}, combo...))
+			return true
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(all) != 126 || !reflect.DeepEqual(all, ranged) {
+		t.Errorf("Expected ranged iteration to match, got %d and %d combinations", len(all), len(ranged))
+	}
+}
+
+func TestCombinationRankUnrank(t *testing.T) {
+	combinations := NewCombinations([]int{0, 1, 2, 3, 4, 5, 6}, 3)
+	for combinations.Next() {
+		rank, err := CombinationRank(combinations.Indices(), 7)
+		if err != nil || rank != combinations.Rank() {
+			t.Errorf("CombinationRank(%v) = %d, %v; expected %d", combinations.Indices(), rank, err, combinations.Rank())
+		}
+		idxs, err := CombinationUnrank(combinations.Rank(), 7, 3)
+		if err != nil || !reflect.DeepEqual(idxs, combinations.Indices()) {
+			t.Errorf("CombinationUnrank(%d) = %v, %v; expected %v", combinations.Rank(), idxs, err, combinations.Indices())
+		}
+	}
+
+	// C(52,5) 的最後一個組合
+	idxs, err := CombinationUnrank(2598959, 52, 5)
+	if err != nil || !reflect.DeepEqual(idxs, []int{47, 48, 49, 50, 51}) {
+		t.Errorf("CombinationUnrank(2598959, 52, 5) = %v, %v", idxs, err)
+	}
+	if _, err := CombinationUnrank(2598960, 52, 5); err == nil {
+		t.Errorf("Expected error for out of range rank")
+	}
+	if _, err := CombinationRank([]int{2, 1}, 5); err == nil {
+		t.Errorf("Expected error for unsorted indices")
+	}
+}
+
+func TestBinomial(t *testing.T) {
+	tests := []struct {
+		n, k     int
+		expected int64
+	}{
+		{52, 5, 2598960},
+		{47, 5, 1533939},
+		{5, 0, 1},
+		{5, 6, 0},
+		{5, -1, 0},
+	}
+	for _, tt := range tests {
+		if result := Binomial(tt.n, tt.k); result != tt.expected {
+			t.Errorf("Binomial(%d, %d) = %d; expected %d", tt.n, tt.k, result, tt.expected)
+		}
+	}
+}
@@ -2,43 +2,59 @@ package utility
 
 import (
 	"fmt"
-	"math/rand"
 	"strconv"
 	"strings"
-	"time"
 )
 
-var (
-	rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
-)
+// 以下函式使用全域亂數, 需要指定亂數來源時使用 RNG 結尾的版本, rng 為 nil 時同樣使用全域亂數
 
 // RandomFloatBetweenInts 從兩個整數之間生成一個隨機float64
 func RandomFloatBetweenInts(min, max int) (float64, error) {
+	return RandomFloatBetweenIntsRNG(nil, min, max)
+}
+
+// RandomFloatBetweenIntsRNG 同 RandomFloatBetweenInts, 從 rng 取亂數
+func RandomFloatBetweenIntsRNG(rng RNG, min, max int) (float64, error) {
 	if min > max {
 		return 0, fmt.Errorf("RandomFloatBetweenInts傳入值不符合規則 最小值<=最大值")
 	}
-	return float64(min) + rnd.Float64()*(float64(max)-float64(min)), nil
+	return float64(min) + WrapRNG(rng).Float64()*(float64(max)-float64(min)), nil
 }
 
 // 從兩個整數之間生成一個隨機int 傳入0,100會回傳0到100(包含0和100)的隨機整數
 func GetRandomIntFromMinMax(min, max int) (int, error) {
+	return GetRandomIntFromMinMaxRNG(nil, min, max)
+}
+
+// GetRandomIntFromMinMaxRNG 同 GetRandomIntFromMinMax, 從 rng 取亂數
+func GetRandomIntFromMinMaxRNG(rng RNG, min, max int) (int, error) {
 	if min > max {
 		return 0, fmt.Errorf("RandomIntBetweenInts傳入值不符合規則 最小值<=最大值")
 	}
-	return rnd.Intn(max-min+1) + min, nil
+	return WrapRNG(rng).IntN(max-min+1) + min, nil
 }
 
 // GetRandomTFromSlice 傳入泛型切片，返回隨機1個元素。
 func GetRandomTFromSlice[T any](slice []T) (T, error) {
+	return GetRandomTFromSliceRNG(nil, slice)
+}
+
+// GetRandomTFromSliceRNG 同 GetRandomTFromSlice, 從 rng 取亂數
+func GetRandomTFromSliceRNG[T any](rng RNG, slice []T) (T, error) {
 	if len(slice) == 0 {
 		var value T
 		return value, fmt.Errorf("GetRandomTFromSlice傳入參數錯誤")
 	}
-	return slice[rnd.Intn(len(slice))], nil
+	return slice[WrapRNG(rng).IntN(len(slice))], nil
 }
 
 // 從map中取隨機key值出來
 func GetRndKeyFromMap[K comparable, V any](m map[K]V) K {
+	return GetRndKeyFromMapRNG(nil, m)
+}
+
+// GetRndKeyFromMapRNG 同 GetRndKeyFromMap, 從 rng 取亂數
+func GetRndKeyFromMapRNG[K comparable, V any](rng RNG, m map[K]V) K {
 	keys := make([]K, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
@@ -49,11 +65,16 @@ func GetRndKeyFromMap[K comparable, V any](m map[K]V) K {
 		return defaultK // 如果map為空, 返回K類型的零值
 	}
 
-	return keys[rnd.Intn(len(keys))] // 隨機選擇一個鍵並返回
+	return keys[WrapRNG(rng).IntN(len(keys))] // 隨機選擇一個鍵並返回
 }
 
 // 從map中取隨機value值出來
 func GetRndValueFromMap[K comparable, V any](m map[K]V) V {
+	return GetRndValueFromMapRNG(nil, m)
+}
+
+// GetRndValueFromMapRNG 同 GetRndValueFromMap, 從 rng 取亂數
+func GetRndValueFromMapRNG[K comparable, V any](rng RNG, m map[K]V) V {
 	values := make([]V, 0, len(m))
 	for _, v := range m {
 		values = append(values, v)
@@ -63,16 +84,26 @@ func GetRndValueFromMap[K comparable, V any](m map[K]V) V {
 		var defaultV V
 		return defaultV // 如果map為空, 返回V類型的零值
 	}
-	return values[rnd.Intn(len(values))] // 隨機選擇一個值並返回
+	return values[WrapRNG(rng).IntN(len(values))] // 隨機選擇一個值並返回
 }
 
 // 傳入機率回傳結果 EX. 傳入0.3就是有30%機率返回true
 func GetProbResult(prob float64) bool {
-	return rnd.Float64() < prob
+	return GetProbResultRNG(nil, prob)
+}
+
+// GetProbResultRNG 同 GetProbResult, 從 rng 取亂數
+func GetProbResultRNG(rng RNG, prob float64) bool {
+	return WrapRNG(rng).Float64() < prob
 }
 
 // 範例: 傳入"100~200" 回傳100~199之間的int
 func GetRndIntFromRangeStr(input string, delimiter string) (int, error) {
+	return GetRndIntFromRangeStrRNG(nil, input, delimiter)
+}
+
+// GetRndIntFromRangeStrRNG 同 GetRndIntFromRangeStr, 從 rng 取亂數
+func GetRndIntFromRangeStrRNG(rng RNG, input string, delimiter string) (int, error) {
 	parts := strings.Split(input, delimiter)
 	if len(parts) != 2 {
 		return 0, fmt.Errorf("傳入字串要剛好只有一個分隔符號")
@@ -85,7 +116,7 @@ func GetRndIntFromRangeStr(input string, delimiter string) (int, error) {
 	if min > max {
 		return 0, fmt.Errorf("傳入字串的最小不可大於最大值")
 	}
-	rndInt, err := GetRandomIntFromMinMax(min, max)
+	rndInt, err := GetRandomIntFromMinMaxRNG(rng, min, max)
 	if err != nil {
 		return 0, err
 	}
@@ -94,6 +125,11 @@ func GetRndIntFromRangeStr(input string, delimiter string) (int, error) {
 
 // 範例: 傳入"100,200,300" 回傳隨機一個值, 例如200
 func GetRndIntFromString(input string, delimiter string) (int, error) {
+	return GetRndIntFromStringRNG(nil, input, delimiter)
+}
+
+// GetRndIntFromStringRNG 同 GetRndIntFromString, 從 rng 取亂數
+func GetRndIntFromStringRNG(rng RNG, input string, delimiter string) (int, error) {
 	// 檢查 input 不為空字串
 	if input == "" {
 		return 0, fmt.Errorf("input string is empty or incorrect format")
@@ -116,11 +152,16 @@ func GetRndIntFromString(input string, delimiter string) (int, error) {
 		return 0, fmt.Errorf("no valid numbers found in the input string")
 	}
 
-	return validNumbers[rnd.Intn(len(validNumbers))], nil
+	return validNumbers[WrapRNG(rng).IntN(len(validNumbers))], nil
 }
 
 // 範例: 傳入"100,200,300" 回傳隨機一個字串, 例如"200"
 func GetRndStrFromString(input string, delimiter string) (string, error) {
+	return GetRndStrFromStringRNG(nil, input, delimiter)
+}
+
+// GetRndStrFromStringRNG 同 GetRndStrFromString, 從 rng 取亂數
+func GetRndStrFromStringRNG(rng RNG, input string, delimiter string) (string, error) {
 	// 檢查 input 不為空字串
 	if input == "" {
 		return "", fmt.Errorf("input string is empty or incorrect format")
@@ -140,5 +181,5 @@ func GetRndStrFromString(input string, delimiter string) (string, error) {
 		return "", fmt.Errorf("no valid parts after string splits")
 	}
 
-	return validParts[rnd.Intn(len(validParts))], nil
+	return validParts[WrapRNG(rng).IntN(len(validParts))], nil
 }
@@ -0,0 +1,71 @@
+package utility
+
+import (
+	"math/big"
+	"sort"
+)
+
+// 將分數格式化為固定位數的小數, 最後一位四捨五入(0.5 往遠離0的方向進位)
+//
+//	1/3, 4 -> "0.3333"
+func FormatRat(r *big.Rat, decimalPlaces int) string {
+	return r.FloatString(decimalPlaces)
+}
+
+// 將分數格式化為固定位數的百分比
+//
+//	1/8, 1 -> "12.5%"
+func FormatPercent(r *big.Rat, decimalPlaces int) string {
+	percent := new(big.Rat).Mul(r, big.NewRat(100, 1))
+	return percent.FloatString(decimalPlaces) + "%"
+}
+
+// 以最大餘數法將所有分數捨入到指定位數, 捨入後的總和等於原總和四捨五入到同樣位數的結果
+//
+// 先全部無條件捨去, 再把差額一單位一單位補給餘數最大的值, 餘數相同時補給前面的值;
+// 用來讓報表上的機率加總剛好是1
+//
+//	[1/3, 1/3, 1/3], 2 -> [0.34, 0.33, 0.33]
+func RoundRats(values []*big.Rat, decimalPlaces int) []*big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimalPlaces)), nil)
+	scaleRat := new(big.Rat).SetInt(scale)
+
+	units := make([]*big.Int, len(values))
+	remainders := make([]*big.Rat, len(values))
+	sum := new(big.Rat)
+	floorSum := new(big.Int)
+	for i, value := range values {
+		sum.Add(sum, value)
+		scaled := new(big.Rat).Mul(value, scaleRat)
+		units[i] = floorRat(scaled)
+		floorSum.Add(floorSum, units[i])
+		remainders[i] = scaled.Sub(scaled, new(big.Rat).SetInt(units[i]))
+	}
+
+	// 總和四捨五入後需要的單位數
+	half := big.NewRat(1, 2)
+	target := floorRat(new(big.Rat).Add(new(big.Rat).Mul(sum, scaleRat), half))
+	extra := new(big.Int).Sub(target, floorSum).Int64()
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]].Cmp(remainders[order[j]]) > 0
+	})
+	for i := int64(0); i < extra && i < int64(len(order)); i++ {
+		units[order[i]].Add(units[order[i]], big.NewInt(1))
+	}
+
+	rounded := make([]*big.Rat, len(values))
+	for i, unit := range units {
+		rounded[i] = new(big.Rat).SetFrac(unit, scale)
+	}
+	return rounded
+}
+
+// 分數無條件捨去到整數(往負無限大), 分母一定是正數所以 Euclidean 除法即為 floor
+func floorRat(r *big.Rat) *big.Int {
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
@@ -0,0 +1,59 @@
+package utility
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormatRat(t *testing.T) {
+	tests := []struct {
+		value         *big.Rat
+		decimalPlaces int
+		expected      string
+		percent       string
+	}{
+		{big.NewRat(1, 3), 4, "0.3333", "33.3333%"},
+		{big.NewRat(2, 3), 2, "0.67", "66.67%"},
+		{big.NewRat(1, 8), 1, "0.1", "12.5%"},
+		{big.NewRat(1, 2), 0, "1", "50%"},
+		{big.NewRat(-1, 2), 0, "-1", "-50%"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatRat(tt.value, tt.decimalPlaces); result != tt.expected {
+			t.Errorf("FormatRat(%v, %d) = %s; expected %s", tt.value, tt.decimalPlaces, result, tt.expected)
+		}
+		if result := FormatPercent(tt.value, tt.decimalPlaces); result != tt.percent {
+			t.Errorf("FormatPercent(%v, %d) = %s; expected %s", tt.value, tt.decimalPlaces, result, tt.percent)
+		}
+	}
+}
+
+func TestRoundRats(t *testing.T) {
+	tests := []struct {
+		values        []*big.Rat
+		decimalPlaces int
+		expected      []string
+	}{
+		{[]*big.Rat{big.NewRat(1, 3), big.NewRat(1, 3), big.NewRat(1, 3)}, 2, []string{"0.34", "0.33", "0.33"}},
+		{[]*big.Rat{big.NewRat(1, 6), big.NewRat(1, 6), big.NewRat(2, 3)}, 1, []string{"0.2", "0.2", "0.6"}},
+		{[]*big.Rat{big.NewRat(1, 8), big.NewRat(7, 8)}, 2, []string{"0.13", "0.87"}},
+		{[]*big.Rat{big.NewRat(1, 4), big.NewRat(1, 4)}, 1, []string{"0.3", "0.2"}},
+		{[]*big.Rat{big.NewRat(0, 1), big.NewRat(1, 1)}, 3, []string{"0.000", "1.000"}},
+	}
+
+	for _, tt := range tests {
+		rounded := RoundRats(tt.values, tt.decimalPlaces)
+		sum, roundedSum := new(big.Rat), new(big.Rat)
+		for i, value := range rounded {
+			if result := value.FloatString(tt.decimalPlaces); result != tt.expected[i] {
+				t.Errorf("RoundRats(%v, %d)[%d] = %s; expected %s", tt.values, tt.decimalPlaces, i, result, tt.expected[i])
+			}
+			sum.Add(sum, tt.values[i])
+			roundedSum.Add(roundedSum, value)
+		}
+		if sum.FloatString(tt.decimalPlaces) != roundedSum.FloatString(tt.decimalPlaces) {
+			t.Errorf("RoundRats(%v, %d) sums to %s; expected %s", tt.values, tt.decimalPlaces, roundedSum.FloatString(tt.decimalPlaces), sum.FloatString(tt.decimalPlaces))
+		}
+	}
+}
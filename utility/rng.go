@@ -0,0 +1,105 @@
+package utility
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math-discard-card/i18n"
+	"math/rand/v2"
+)
+
+// RNG 可注入的亂數來源, 與 math/rand/v2 的 Source 相同, 可以直接傳給 rand.New
+//
+// PCG、ChaCha8 不可同時在多個 goroutine 中使用; CryptoRNG 與 nil(全域亂數) 可以
+type RNG interface {
+	Uint64() uint64
+}
+
+// RNGNames 可以用 NewRNG 建立的亂數演算法
+var RNGNames = []string{"pcg", "chacha8", "crypto"}
+
+// NewRNG 依名稱建立亂數來源, pcg、chacha8 以 seed 決定序列, crypto 不使用 seed
+func NewRNG(name string, seed int64) (RNG, error) {
+	switch name {
+	case "pcg", "":
+		return NewPCG(seed), nil
+	case "chacha8":
+		return NewChaCha8(seed), nil
+	case "crypto":
+		return NewCryptoRNG(), nil
+	default:
+		return nil, i18n.Errorf("error.rng", name)
+	}
+}
+
+// NewPCG 以 seed 建立 PCG 亂數, 速度快, 適合測試、重播與模擬
+func NewPCG(seed int64) RNG {
+	return rand.NewPCG(uint64(seed), SplitMix64(uint64(seed)))
+}
+
+// NewChaCha8 以 seed 建立 ChaCha8 亂數, 序列可以重現且難以預測
+//
+// 32 bytes 的金鑰由 seed 以 splitmix64 展開
+func NewChaCha8(seed int64) RNG {
+	var key [32]byte
+	state := uint64(seed)
+	for i := 0; i < len(key); i += 8 {
+		state = SplitMix64(state)
+		binary.LittleEndian.PutUint64(key[i:], state)
+	}
+	return rand.NewChaCha8(key)
+}
+
+// CryptoRNG 以 crypto/rand 產生亂數, 給正式發牌使用, 無法以種子重現
+type CryptoRNG struct{}
+
+// NewCryptoRNG 建立使用 crypto/rand 的亂數來源
+func NewCryptoRNG() RNG {
+	return CryptoRNG{}
+}
+
+func (CryptoRNG) Uint64() uint64 {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		// 系統亂數來源無法使用時不能繼續發牌
+		panic(fmt.Errorf("讀取系統亂數失敗: %w", err))
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// 沒有指定亂數來源時使用 math/rand/v2 的全域亂數
+type globalRNG struct{}
+
+func (globalRNG) Uint64() uint64 {
+	return rand.Uint64()
+}
+
+// 全域亂數的亂數工具, 可以同時在多個 goroutine 中使用
+var globalRand = rand.New(globalRNG{})
+
+// WrapRNG 以 rng 建立亂數工具, rng 為 nil 時使用全域亂數, 本身已經是 *rand.Rand 時直接使用
+//
+// *rand.Rand 也是 RNG, 需要重複取亂數時先包裝一次再傳入, 避免每次呼叫都配置
+func WrapRNG(rng RNG) *rand.Rand {
+	switch rng := rng.(type) {
+	case nil:
+		return globalRand
+	case *rand.Rand:
+		return rng
+	default:
+		return rand.New(rng)
+	}
+}
+
+// Shuffle 以 rng 打亂 n 個元素的順序, rng 為 nil 時使用全域亂數
+func Shuffle(rng RNG, n int, swap func(i, j int)) {
+	WrapRNG(rng).Shuffle(n, swap)
+}
+
+// SplitMix64 splitmix64 混合, 用來把一個種子展開成多個不相關的數值
+func SplitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,110 @@
+package utility
+
+import (
+	"fmt"
+	"math-discard-card/i18n"
+	"reflect"
+	"testing"
+)
+
+// 從 rng 取前幾個數值
+func draws(rng RNG, n int) []uint64 {
+	values := make([]uint64, n)
+	for i := range values {
+		values[i] = rng.Uint64()
+	}
+	return values
+}
+
+func TestNewRNG(t *testing.T) {
+	tests := []struct {
+		name         string
+		reproducible bool
+		valid        bool
+	}{
+		{"", true, true},
+		{"pcg", true, true},
+		{"chacha8", true, true},
+		{"crypto", false, true},
+		{"mt19937", false, false},
+	}
+
+	for _, tt := range tests {
+		first, err := NewRNG(tt.name, 42)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("NewRNG(%q) expected an error", tt.name)
+			} else if msg := i18n.Message(i18n.En, err); msg != fmt.Sprintf("Unknown random number algorithm: %q", tt.name) {
+				t.Errorf("NewRNG(%q) expected a localized error, got %q", tt.name, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("NewRNG(%q) unexpected error: %v", tt.name, err)
+		}
+		second, _ := NewRNG(tt.name, 42)
+		other, _ := NewRNG(tt.name, 43)
+		a, b, c := draws(first, 8), draws(second, 8), draws(other, 8)
+		if reflect.DeepEqual(a, b) != tt.reproducible {
+			t.Errorf("NewRNG(%q) same seed reproducible = %v; expected %v", tt.name, !tt.reproducible, tt.reproducible)
+		}
+		if reflect.DeepEqual(a, c) {
+			t.Errorf("NewRNG(%q) expected different seeds to give different values", tt.name)
+		}
+	}
+
+	if reflect.DeepEqual(draws(NewPCG(1), 8), draws(NewChaCha8(1), 8)) {
+		t.Errorf("expected PCG and ChaCha8 to give different values")
+	}
+}
+
+func TestRandomWithRNG(t *testing.T) {
+	slice := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	pick := func(rng RNG) []any {
+		results := []any{}
+		for i := 0; i < 20; i++ {
+			n, _ := GetRandomIntFromMinMaxRNG(rng, 0, 100)
+			s, _ := GetRandomTFromSliceRNG(rng, slice)
+			results = append(results, n, s, GetProbResultRNG(rng, 0.5))
+		}
+		order := []int{0, 1, 2, 3, 4, 5, 6, 7}
+		Shuffle(rng, len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+		return append(results, order)
+	}
+
+	if !reflect.DeepEqual(pick(NewChaCha8(7)), pick(NewChaCha8(7))) {
+		t.Errorf("expected helpers to be reproducible with the same seed")
+	}
+	if reflect.DeepEqual(pick(NewChaCha8(7)), pick(NewChaCha8(8))) {
+		t.Errorf("expected helpers to differ with different seeds")
+	}
+	// nil 使用全域亂數
+	if n, err := GetRandomIntFromMinMaxRNG(nil, 5, 5); err != nil || n != 5 {
+		t.Errorf("GetRandomIntFromMinMaxRNG(nil, 5, 5) = %d, %v; expected 5", n, err)
+	}
+	// 先包裝一次與每次傳入原本的亂數來源結果相同
+	if !reflect.DeepEqual(pick(WrapRNG(NewPCG(7))), pick(NewPCG(7))) {
+		t.Errorf("expected a wrapped RNG to give the same values")
+	}
+}
+
+func TestWrapRNG(t *testing.T) {
+	if WrapRNG(nil) != globalRand {
+		t.Errorf("expected nil to use the global rand")
+	}
+	wrapped := WrapRNG(NewPCG(1))
+	if WrapRNG(wrapped) != wrapped {
+		t.Errorf("expected a *rand.Rand to be reused")
+	}
+	order := make([]int, 52)
+	allocs := testing.AllocsPerRun(100, func() {
+		Shuffle(wrapped, len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	})
+	if allocs != 0 {
+		t.Errorf("expected shuffling with a wrapped RNG not to allocate, got %v allocs", allocs)
+	}
+}